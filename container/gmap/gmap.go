@@ -7,11 +7,85 @@
 // Package gmap provides most commonly used map container which also support concurrent-safe/unsafe switch feature.
 package gmap
 
+import (
+	"io"
+	"reflect"
+
+	"github.com/gogf/gf/v2/container/gset"
+	"github.com/gogf/gf/v2/container/gtype"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/internal/json"
+)
+
 type (
 	Map     = AnyAnyMap // Map is alias of AnyAnyMap.
 	HashMap = AnyAnyMap // HashMap is alias of AnyAnyMap.
+	Set     = gset.Set  // Set is alias of gset.Set, which is a set backed by map[interface{}]struct{}.
+)
+
+// Entry is a key-value pair of the map.
+type Entry struct {
+	Key   interface{}
+	Value interface{}
+}
+
+// MapStats is the usage statistics of the map returned by Map.Stats.
+type MapStats struct {
+	Size    int   // Size is the current size of the map.
+	Sets    int64 // Sets is the count of setting keys.
+	Gets    int64 // Gets is the count of reading keys by Search, Get and Gets.
+	Hits    int64 // Hits is the count of found keys by the GetOrSet family, LoadOrStore and GetOrLoad.
+	Misses  int64 // Misses is the count of missing keys by the GetOrSet family, LoadOrStore and GetOrLoad.
+	Removes int64 // Removes is the count of deleted keys.
+}
+
+// EventType is the type of Event.
+type EventType int
+
+const (
+	EventSet    EventType = iota + 1 // EventSet is the event of setting a key.
+	EventRemove                      // EventRemove is the event of deleting a key.
+	EventClear                       // EventClear is the event of clearing the map.
 )
 
+// Event is a change of the map, which is sent by the channel returned by Map.Watch.
+// The Key, Old and New are nil for EventClear, and the New is nil for EventRemove.
+type Event struct {
+	Type EventType
+	Key  interface{}
+	Old  interface{}
+	New  interface{}
+}
+
+// iterationSeed holds the *int64 seed set by SetIterationSeed, or nil if it is not set.
+var iterationSeed = gtype.NewInterface()
+
+// SetIterationSeed makes the iteration order of Map deterministic for given `seed`,
+// which is useful for reproducing test failures. After it is set, the Iterator, IteratorE and IteratorSnapshot
+// of Map iterate the keys in a pseudo-random order determined by the hashes of keys and `seed`, which is the
+// same across runs for the same keys and `seed`.
+//
+// Note that it sorts the keys before each iteration, so it should not be used in production.
+// It affects all the Maps of the process, and it can be unset by ResetIterationSeed.
+func SetIterationSeed(seed int64) {
+	iterationSeed.Set(&seed)
+}
+
+// ResetIterationSeed unsets the seed set by SetIterationSeed, after which the iteration order
+// of Map is random as the native map.
+func ResetIterationSeed() {
+	iterationSeed.Set((*int64)(nil))
+}
+
+// getIterationSeed returns the seed set by SetIterationSeed, and `ok` is false if it is not set.
+func getIterationSeed() (seed int64, ok bool) {
+	if p, _ := iterationSeed.Val().(*int64); p != nil {
+		return *p, true
+	}
+	return 0, false
+}
+
 // New creates and returns an empty hash map.
 // The parameter `safe` is used to specify whether using map in concurrent-safety,
 // which is false in default.
@@ -28,6 +102,134 @@ func NewFrom(data map[interface{}]interface{}, safe ...bool) *Map {
 	return NewAnyAnyMapFrom(data, safe...)
 }
 
+// NewFromArray creates and returns a hash map from given `keys` and their `values` of the same indexes.
+// The keys having no value of the same index are set to nil, and the values having no key are ignored.
+// The parameter `safe` is used to specify whether using map in concurrent-safety,
+// which is false in default.
+func NewFromArray(keys, values []interface{}, safe ...bool) *Map {
+	return NewFromArrayWithDefault(keys, values, nil, safe...)
+}
+
+// NewFromArrayWithDefault creates and returns a hash map from given `keys` and their `values`
+// of the same indexes, just like NewFromArray, but the keys having no value of the same index
+// are set to `def` instead of nil.
+// The parameter `safe` is used to specify whether using map in concurrent-safety,
+// which is false in default.
+func NewFromArrayWithDefault(keys, values []interface{}, def interface{}, safe ...bool) *Map {
+	data := make(map[interface{}]interface{}, len(keys))
+	for i, key := range keys {
+		if i < len(values) {
+			data[key] = values[i]
+		} else {
+			data[key] = def
+		}
+	}
+	return NewAnyAnyMapFrom(data, safe...)
+}
+
+// NewFromJSON creates and returns a hash map from given JSON object `data`.
+// The keys of the map are strings, and the nested JSON objects are kept as map[string]interface{} values.
+// It returns an error if `data` is not a JSON object.
+// The parameter `safe` is used to specify whether using map in concurrent-safety,
+// which is false in default.
+func NewFromJSON(data []byte, safe ...bool) (*Map, error) {
+	var object map[string]interface{}
+	if err := json.UnmarshalUseNumber(data, &object); err != nil {
+		return nil, err
+	}
+	if object == nil {
+		return nil, gerror.NewCode(gcode.CodeInvalidParameter, `JSON data is not an object`)
+	}
+	m := NewAnyAnyMap(safe...)
+	for k, v := range object {
+		m.data[k] = v
+	}
+	return m, nil
+}
+
+// NewFromJSONStream creates and returns a hash map from JSON object read from `reader`, just like NewFromJSON,
+// but it decodes the JSON object key by key and sets each key-value to the map once it is decoded,
+// so that it does not need to read the whole JSON data into memory, which is useful for very large objects.
+// It returns an error if the JSON data is not an object.
+// The parameter `safe` is used to specify whether using map in concurrent-safety,
+// which is false in default.
+func NewFromJSONStream(reader io.Reader, safe ...bool) (*Map, error) {
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, gerror.Wrap(err, `reading JSON object failed`)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, gerror.NewCode(gcode.CodeInvalidParameter, `JSON data is not an object`)
+	}
+	m := NewAnyAnyMap(safe...)
+	for decoder.More() {
+		if token, err = decoder.Token(); err != nil {
+			return nil, gerror.Wrap(err, `reading JSON object key failed`)
+		}
+		var (
+			key   = token.(string)
+			value interface{}
+		)
+		if err = decoder.Decode(&value); err != nil {
+			return nil, gerror.Wrapf(err, `reading value of JSON object key "%s" failed`, key)
+		}
+		m.data[key] = value
+	}
+	if _, err = decoder.Token(); err != nil {
+		return nil, gerror.Wrap(err, `reading JSON object end failed`)
+	}
+	return m, nil
+}
+
+// NewSet creates and returns an empty set, which is the same as gset.NewSet.
+// The parameter `safe` is used to specify whether using set in concurrent-safety,
+// which is false in default.
+func NewSet(safe ...bool) *Set {
+	return gset.NewSet(safe...)
+}
+
+// NewSetFrom creates and returns a set from `items`, which is the same as gset.NewFrom.
+// The parameter `safe` is used to specify whether using set in concurrent-safety,
+// which is false in default.
+func NewSetFrom(items interface{}, safe ...bool) *Set {
+	return gset.NewFrom(items, safe...)
+}
+
+// Transfer moves the key-values of `keys` from map `src` to map `dst` atomically, and returns the count of
+// moved keys. The keys not existing in `src` are ignored, and the values of the existing keys in `dst` are
+// overwritten. Both maps are locked within one critical section in a fixed order, so that transferring
+// between them in both directions concurrently does not deadlock.
+func Transfer(src, dst *Map, keys []interface{}) int {
+	if src == nil || dst == nil || src == dst {
+		return 0
+	}
+	src.checkFrozen()
+	dst.checkFrozen()
+	first, second := src, dst
+	if reflect.ValueOf(first).Pointer() > reflect.ValueOf(second).Pointer() {
+		first, second = second, first
+	}
+	first.mu.Lock()
+	defer first.mu.Unlock()
+	second.mu.Lock()
+	defer second.mu.Unlock()
+	count := 0
+	for _, key := range keys {
+		value, found := src.doRemove(key)
+		if !found {
+			continue
+		}
+		if dst.data == nil {
+			dst.data = make(map[interface{}]interface{})
+		}
+		dst.doSet(key, value)
+		count++
+	}
+	return count
+}
+
 // NewHashMap creates and returns an empty hash map.
 // The parameter `safe` is used to specify whether using map in concurrent-safety,
 // which is false in default.
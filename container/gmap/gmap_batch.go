@@ -0,0 +1,94 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with gm file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gmap
+
+import (
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+)
+
+// Batch stages a group of changes and preconditions, which are applied to a Map atomically
+// by Apply: either all the changes are applied, or none of them if any precondition fails.
+//
+// Batch itself is not concurrent-safe, and it can be applied to multiple maps or multiple times.
+type Batch struct {
+	operations    []gBatchOperation
+	preconditions []gBatchPrecondition
+}
+
+// gBatchOperation is a staged change of Batch.
+type gBatchOperation struct {
+	key    interface{}
+	value  interface{}
+	remove bool
+}
+
+// gBatchPrecondition is a staged precondition of Batch.
+type gBatchPrecondition struct {
+	key   interface{}
+	check func(value interface{}, found bool) bool
+}
+
+// NewBatch creates and returns an empty batch.
+func NewBatch() *Batch {
+	return &Batch{}
+}
+
+// Set stages setting key-value to the map, and returns the batch itself for chaining.
+func (b *Batch) Set(key interface{}, value interface{}) *Batch {
+	b.operations = append(b.operations, gBatchOperation{key: key, value: value})
+	return b
+}
+
+// Remove stages deleting `key` from the map, and returns the batch itself for chaining.
+func (b *Batch) Remove(key interface{}) *Batch {
+	b.operations = append(b.operations, gBatchOperation{key: key, remove: true})
+	return b
+}
+
+// Require stages precondition function `check` on the current value of `key`, and returns the batch
+// itself for chaining. The `found` of `check` is false if the `key` does not exist in the map.
+// The batch is not applied if `check` returns false.
+func (b *Batch) Require(key interface{}, check func(value interface{}, found bool) bool) *Batch {
+	b.preconditions = append(b.preconditions, gBatchPrecondition{key: key, check: check})
+	return b
+}
+
+// Len returns the count of the staged changes.
+func (b *Batch) Len() int {
+	return len(b.operations)
+}
+
+// Apply applies the staged changes to map `m` in staged order within one RWMutex.Lock of `m`.
+//
+// All the preconditions are checked against the current values of the map before any change
+// is applied, and it returns an error leaving the map unchanged if any precondition fails.
+// Note that the preconditions are called within the lock, so they should not call any method of the map.
+func (b *Batch) Apply(m *Map) error {
+	m.checkFrozen()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, precondition := range b.preconditions {
+		value, found := m.data[precondition.key]
+		if !precondition.check(value, found) {
+			return gerror.NewCodef(
+				gcode.CodeValidationFailed, `precondition failed for key "%v"`, precondition.key,
+			)
+		}
+	}
+	if m.data == nil {
+		m.data = make(map[interface{}]interface{})
+	}
+	for _, operation := range b.operations {
+		if operation.remove {
+			m.doRemove(operation.key)
+		} else {
+			m.doSet(operation.key, operation.value)
+		}
+	}
+	return nil
+}
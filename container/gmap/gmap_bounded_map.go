@@ -0,0 +1,153 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with gm file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gmap
+
+import (
+	"github.com/gogf/gf/v2/internal/rwmutex"
+)
+
+// BoundedMap is a map with limited capacity that rejects adding new keys when it is full,
+// unlike LRUMap which evicts the existing items. Overwriting the existing keys is always allowed.
+type BoundedMap struct {
+	mu   rwmutex.RWMutex
+	data map[interface{}]interface{}
+	max  int
+}
+
+// NewBoundedMap creates and returns an empty bounded map which holds at most `max` keys.
+// The map has no capacity limit if `max` <= 0.
+// The parameter `safe` is used to specify whether using map in concurrent-safety,
+// which is false in default.
+func NewBoundedMap(max int, safe ...bool) *BoundedMap {
+	return &BoundedMap{
+		mu:   rwmutex.Create(safe...),
+		data: make(map[interface{}]interface{}),
+		max:  max,
+	}
+}
+
+// Set sets key-value to the map and returns true.
+// It does nothing and returns false if `key` is a new key and the map is full.
+func (m *BoundedMap) Set(key interface{}, value interface{}) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.data == nil {
+		m.data = make(map[interface{}]interface{})
+	}
+	if _, ok := m.data[key]; !ok && m.max > 0 && len(m.data) >= m.max {
+		return false
+	}
+	m.data[key] = value
+	return true
+}
+
+// Search searches the map with given `key`.
+// Second return parameter `found` is true if key was found, otherwise false.
+func (m *BoundedMap) Search(key interface{}) (value interface{}, found bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	value, found = m.data[key]
+	return
+}
+
+// Get returns the value by given `key`.
+func (m *BoundedMap) Get(key interface{}) (value interface{}) {
+	value, _ = m.Search(key)
+	return
+}
+
+// Contains checks whether a key exists.
+// It returns true if the `key` exists, or else false.
+func (m *BoundedMap) Contains(key interface{}) bool {
+	_, ok := m.Search(key)
+	return ok
+}
+
+// Remove deletes value from map by given `key`, and return this deleted value.
+func (m *BoundedMap) Remove(key interface{}) (value interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if value, ok := m.data[key]; ok {
+		delete(m.data, key)
+		return value
+	}
+	return nil
+}
+
+// Keys returns all keys of the map as a slice.
+func (m *BoundedMap) Keys() []interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	keys := make([]interface{}, 0, len(m.data))
+	for key := range m.data {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Map returns a copy of the underlying data of the map.
+func (m *BoundedMap) Map() map[interface{}]interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	data := make(map[interface{}]interface{}, len(m.data))
+	for k, v := range m.data {
+		data[k] = v
+	}
+	return data
+}
+
+// Iterator iterates the map readonly with custom callback function `f`.
+// If `f` returns true, then it continues iterating; or false to stop.
+func (m *BoundedMap) Iterator(f func(key, value interface{}) bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for k, v := range m.data {
+		if !f(k, v) {
+			break
+		}
+	}
+}
+
+// Size returns the size of the map.
+func (m *BoundedMap) Size() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.data)
+}
+
+// Cap returns the capacity of the map, which is 0 if the map has no capacity limit.
+func (m *BoundedMap) Cap() int {
+	if m.max <= 0 {
+		return 0
+	}
+	return m.max
+}
+
+// Available returns the count of new keys that can still be added to the map.
+// It returns -1 if the map has no capacity limit.
+func (m *BoundedMap) Available() int {
+	if m.max <= 0 {
+		return -1
+	}
+	if available := m.max - m.Size(); available > 0 {
+		return available
+	}
+	return 0
+}
+
+// IsEmpty checks whether the map is empty.
+// It returns true if map is empty, or else false.
+func (m *BoundedMap) IsEmpty() bool {
+	return m.Size() == 0
+}
+
+// Clear deletes all data of the map.
+func (m *BoundedMap) Clear() {
+	m.mu.Lock()
+	m.data = make(map[interface{}]interface{})
+	m.mu.Unlock()
+}
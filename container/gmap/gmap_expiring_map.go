@@ -0,0 +1,213 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with gm file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gmap
+
+import (
+	"time"
+
+	"github.com/gogf/gf/v2/container/gtype"
+	"github.com/gogf/gf/v2/internal/rwmutex"
+)
+
+// ExpiringMap is a map whose items can be expired after specified duration.
+//
+// The expired items are deleted lazily when they are accessed, and also periodically by a background
+// sweeper goroutine if the map is created by NewExpiringMap in concurrent-safety.
+// Note that the background sweeper should be stopped using Close if the map is no longer in use.
+type ExpiringMap struct {
+	mu        rwmutex.RWMutex
+	data      map[interface{}]*gExpiringMapItem
+	closed    gtype.Bool
+	closeChan chan struct{}
+}
+
+type gExpiringMapItem struct {
+	value    interface{}
+	deadline int64 // Expiring timestamp in nanoseconds, which is 0 if the item never expires.
+}
+
+const (
+	// defaultExpiringMapSweepInterval is the interval for background sweeper deleting expired items.
+	defaultExpiringMapSweepInterval = time.Second
+)
+
+// NewExpiringMap creates and returns an empty expiring map.
+// The parameter `safe` is used to specify whether using map in concurrent-safety,
+// which is false in default.
+//
+// The background sweeper goroutine is started only if the map is in concurrent-safety, as it accesses
+// the map concurrently, and Close is required to stop it. The expired items of the un-concurrent-safe
+// map are only deleted lazily when they are accessed, or by calling Sweep manually.
+func NewExpiringMap(safe ...bool) *ExpiringMap {
+	m := &ExpiringMap{
+		mu:        rwmutex.Create(safe...),
+		data:      make(map[interface{}]*gExpiringMapItem),
+		closeChan: make(chan struct{}),
+	}
+	if m.mu.IsSafe() {
+		go m.doSweepLoop(defaultExpiringMapSweepInterval)
+	}
+	return m
+}
+
+// Set sets key-value to the map, which never expires.
+func (m *ExpiringMap) Set(key interface{}, value interface{}) {
+	m.SetWithTTL(key, value, 0)
+}
+
+// SetWithTTL sets key-value to the map, which expires after `ttl`.
+// The item never expires if `ttl` <= 0.
+func (m *ExpiringMap) SetWithTTL(key interface{}, value interface{}, ttl time.Duration) {
+	item := &gExpiringMapItem{value: value}
+	if ttl > 0 {
+		item.deadline = time.Now().Add(ttl).UnixNano()
+	}
+	m.mu.Lock()
+	if m.data == nil {
+		m.data = make(map[interface{}]*gExpiringMapItem)
+	}
+	m.data[key] = item
+	m.mu.Unlock()
+}
+
+// Search searches the map with given `key`.
+// Second return parameter `found` is true if key was found and not expired, otherwise false.
+// The expired item of `key` is deleted from the map.
+func (m *ExpiringMap) Search(key interface{}) (value interface{}, found bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	item, ok := m.data[key]
+	if !ok {
+		return nil, false
+	}
+	if item.isExpired(time.Now().UnixNano()) {
+		delete(m.data, key)
+		return nil, false
+	}
+	return item.value, true
+}
+
+// Get returns the value by given `key`, or nil if the `key` does not exist or is expired.
+func (m *ExpiringMap) Get(key interface{}) (value interface{}) {
+	value, _ = m.Search(key)
+	return
+}
+
+// Contains checks whether a key exists and is not expired.
+func (m *ExpiringMap) Contains(key interface{}) bool {
+	_, found := m.Search(key)
+	return found
+}
+
+// TTL returns the remaining time to live of given `key`, which is 0 if the item never expires.
+// Second return parameter `found` is false if key was not found or expired.
+func (m *ExpiringMap) TTL(key interface{}) (ttl time.Duration, found bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	item, ok := m.data[key]
+	if !ok {
+		return 0, false
+	}
+	now := time.Now().UnixNano()
+	if item.isExpired(now) {
+		return 0, false
+	}
+	if item.deadline == 0 {
+		return 0, true
+	}
+	return time.Duration(item.deadline - now), true
+}
+
+// Remove deletes value from map by given `key`, and return this deleted value.
+// It returns nil if the item of `key` is expired.
+func (m *ExpiringMap) Remove(key interface{}) (value interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if item, ok := m.data[key]; ok {
+		delete(m.data, key)
+		if !item.isExpired(time.Now().UnixNano()) {
+			value = item.value
+		}
+	}
+	return
+}
+
+// Keys returns all keys of the map that are not expired as a slice.
+func (m *ExpiringMap) Keys() []interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var (
+		now  = time.Now().UnixNano()
+		keys = make([]interface{}, 0, len(m.data))
+	)
+	for key, item := range m.data {
+		if !item.isExpired(now) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Size returns the size of the map.
+// Note that the expired items that are not deleted yet are also counted.
+func (m *ExpiringMap) Size() int {
+	m.mu.RLock()
+	length := len(m.data)
+	m.mu.RUnlock()
+	return length
+}
+
+// Clear deletes all data of the map.
+func (m *ExpiringMap) Clear() {
+	m.mu.Lock()
+	m.data = make(map[interface{}]*gExpiringMapItem)
+	m.mu.Unlock()
+}
+
+// Sweep deletes all expired items from the map and returns the count of deleted items.
+func (m *ExpiringMap) Sweep() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var (
+		now   = time.Now().UnixNano()
+		count = 0
+	)
+	for key, item := range m.data {
+		if item.isExpired(now) {
+			delete(m.data, key)
+			count++
+		}
+	}
+	return count
+}
+
+// Close stops the background sweeper goroutine of the map.
+// The map can still be used after closed, but the expired items are only deleted lazily.
+func (m *ExpiringMap) Close() {
+	if m.closed.Cas(false, true) && m.closeChan != nil {
+		close(m.closeChan)
+	}
+}
+
+// doSweepLoop sweeps expired items of the map every `interval` until the map is closed.
+func (m *ExpiringMap) doSweepLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.closeChan:
+			return
+		case <-ticker.C:
+			m.Sweep()
+		}
+	}
+}
+
+// isExpired checks whether the item is expired at timestamp `now` in nanoseconds.
+func (item *gExpiringMapItem) isExpired(now int64) bool {
+	return item.deadline > 0 && item.deadline <= now
+}
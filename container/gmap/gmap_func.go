@@ -0,0 +1,667 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with gm file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gmap
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+)
+
+// HashKey returns a deterministic 64-bit hash of given map key `key`, which can be used for sharding keys.
+// The equal keys always have the same hash value.
+//
+// The string, bool and integer keys are hashed natively, and the keys of other types are hashed
+// using their string representation by fmt.Sprint.
+func HashKey(key interface{}) uint64 {
+	return hashKey(key)
+}
+
+// hashKey returns a deterministic 64-bit hash of given map key `key`.
+func hashKey(key interface{}) uint64 {
+	switch v := key.(type) {
+	case string:
+		return hashString(v)
+	case bool:
+		if v {
+			return mixUint64(1)
+		}
+		return mixUint64(0)
+	case int:
+		return mixUint64(uint64(v))
+	case int8:
+		return mixUint64(uint64(v))
+	case int16:
+		return mixUint64(uint64(v))
+	case int32:
+		return mixUint64(uint64(v))
+	case int64:
+		return mixUint64(uint64(v))
+	case uint:
+		return mixUint64(uint64(v))
+	case uint8:
+		return mixUint64(uint64(v))
+	case uint16:
+		return mixUint64(uint64(v))
+	case uint32:
+		return mixUint64(uint64(v))
+	case uint64:
+		return mixUint64(v)
+	case uintptr:
+		return mixUint64(uint64(v))
+	default:
+		return hashString(fmt.Sprint(key))
+	}
+}
+
+// hashString returns the FNV-1a hash of string `s`.
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// mixUint64 scrambles the bits of integer `x` using the finalizer of SplitMix64,
+// so that the sequential integers are distributed uniformly.
+func mixUint64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// toFloat64 converts numeric value `value` to float64.
+// The numeric value can be of any integer or float kind, or a numeric string like "42" and json.Number.
+// The second return parameter `ok` is false if `value` is not numeric.
+func toFloat64(value interface{}) (f float64, ok bool) {
+	switch v := value.(type) {
+	case nil:
+		return 0, false
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case string:
+		return parseFloat64(v)
+	case []byte:
+		return parseFloat64(string(v))
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	case reflect.String:
+		return parseFloat64(rv.String())
+	}
+	return 0, false
+}
+
+// toInt converts numeric value `value` to int, which can be of any integer or float kind, or a numeric string.
+// The second return parameter `ok` is false if `value` is not a whole number or overflows int.
+func toInt(value interface{}) (i int, ok bool) {
+	if v, ok := value.(int); ok {
+		return v, true
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i64 := rv.Int()
+		return int(i64), int64(int(i64)) == i64
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u64 := rv.Uint()
+		return int(u64), u64 <= math.MaxInt
+	}
+	f, ok := toFloat64(value)
+	if !ok || f != math.Trunc(f) || f < math.MinInt || f >= math.MaxInt {
+		return 0, false
+	}
+	return int(f), true
+}
+
+// fromFloat64 converts `f` to the same numeric type as `like` if possible, or else it returns `f` as float64.
+// The `f` is converted to integer type only if it is a whole number and does not overflow the type.
+func fromFloat64(f float64, like interface{}) interface{} {
+	if like == nil {
+		return f
+	}
+	rv := reflect.ValueOf(like)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return f
+		}
+		if i := int64(f); !rv.OverflowInt(i) {
+			v := reflect.New(rv.Type()).Elem()
+			v.SetInt(i)
+			return v.Interface()
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 {
+			return f
+		}
+		if u := uint64(f); !rv.OverflowUint(u) {
+			v := reflect.New(rv.Type()).Elem()
+			v.SetUint(u)
+			return v.Interface()
+		}
+	case reflect.Float32, reflect.Float64:
+		if !rv.OverflowFloat(f) {
+			v := reflect.New(rv.Type()).Elem()
+			v.SetFloat(f)
+			return v.Interface()
+		}
+	}
+	return f
+}
+
+// parseFloat64 parses numeric string `s` to float64.
+func parseFloat64(s string) (float64, bool) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	return f, err == nil
+}
+
+// getByPath retrieves the value from nested value `data` by path `segments`, in which each segment is
+// a key of map or an index of slice. It returns nil and false if any segment is missing.
+func getByPath(data interface{}, segments []string) (value interface{}, found bool) {
+	value = data
+	for _, segment := range segments {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if value, found = v[segment]; !found {
+				return nil, false
+			}
+		case map[interface{}]interface{}:
+			if value, found = v[segment]; !found {
+				return nil, false
+			}
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, false
+			}
+			value = v[index]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// setByPath returns a copy of nested value `data` with `value` set by path `segments[index:]`, in which
+// each segment is a key of map or an index of slice. The maps and slices along the path are copied, so
+// `data` is never changed. The missing or nil segments are created as map[string]interface{}.
+func setByPath(data interface{}, segments []string, index int, value interface{}) (interface{}, error) {
+	if index == len(segments) {
+		return value, nil
+	}
+	segment := segments[index]
+	switch v := data.(type) {
+	case nil:
+		next, err := setByPath(nil, segments, index+1, value)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{segment: next}, nil
+
+	case map[string]interface{}:
+		next, err := setByPath(v[segment], segments, index+1, value)
+		if err != nil {
+			return nil, err
+		}
+		copied := make(map[string]interface{}, len(v)+1)
+		for key, item := range v {
+			copied[key] = item
+		}
+		copied[segment] = next
+		return copied, nil
+
+	case map[interface{}]interface{}:
+		next, err := setByPath(v[segment], segments, index+1, value)
+		if err != nil {
+			return nil, err
+		}
+		copied := make(map[interface{}]interface{}, len(v)+1)
+		for key, item := range v {
+			copied[key] = item
+		}
+		copied[segment] = next
+		return copied, nil
+
+	case []interface{}:
+		i, err := strconv.Atoi(segment)
+		if err != nil || i < 0 || i >= len(v) {
+			return nil, gerror.NewCodef(
+				gcode.CodeInvalidParameter,
+				`invalid index "%s" of slice "%s" in path "%s"`,
+				segment, strings.Join(segments[:index], "."), strings.Join(segments, "."),
+			)
+		}
+		next, err := setByPath(v[i], segments, index+1, value)
+		if err != nil {
+			return nil, err
+		}
+		copied := make([]interface{}, len(v))
+		copy(copied, v)
+		copied[i] = next
+		return copied, nil
+
+	default:
+		return nil, gerror.NewCodef(
+			gcode.CodeInvalidParameter,
+			`segment "%s" in path "%s" is type of %T but not a map or slice`,
+			strings.Join(segments[:index], "."), strings.Join(segments, "."), data,
+		)
+	}
+}
+
+// sortKeys sorts `keys` in place with `comparator`, or by their string representation of fmt.Sprint
+// if `comparator` is nil. The keys having the same string representation are ordered by their type names,
+// so that the order is repeatable.
+func sortKeys(keys []interface{}, comparator func(a, b interface{}) int) {
+	if comparator != nil {
+		sort.Slice(keys, func(i, j int) bool {
+			return comparator(keys[i], keys[j]) < 0
+		})
+		return
+	}
+	type sortKey struct {
+		key      interface{}
+		str      string
+		typeName string
+	}
+	sortKeys := make([]sortKey, len(keys))
+	for i, key := range keys {
+		sortKeys[i] = sortKey{key: key, str: fmt.Sprint(key), typeName: fmt.Sprintf("%T", key)}
+	}
+	sort.Slice(sortKeys, func(i, j int) bool {
+		if sortKeys[i].str != sortKeys[j].str {
+			return sortKeys[i].str < sortKeys[j].str
+		}
+		return sortKeys[i].typeName < sortKeys[j].typeName
+	})
+	for i, v := range sortKeys {
+		keys[i] = v.key
+	}
+}
+
+// sortKeysByKind sorts `keys` in place in ascending order by their values if all of them are strings,
+// signed integers, unsigned integers or floats, or else it sorts them by sortKeys with fmt.Sprint.
+func sortKeysByKind(keys []interface{}) {
+	const (
+		kindString = iota + 1
+		kindInt
+		kindUint
+		kindFloat
+	)
+	kindOf := func(key interface{}) int {
+		switch reflect.ValueOf(key).Kind() {
+		case reflect.String:
+			return kindString
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return kindInt
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return kindUint
+		case reflect.Float32, reflect.Float64:
+			return kindFloat
+		default:
+			return 0
+		}
+	}
+	if len(keys) == 0 {
+		return
+	}
+	kind := kindOf(keys[0])
+	for _, key := range keys[1:] {
+		if kindOf(key) != kind {
+			kind = 0
+			break
+		}
+	}
+	var less func(a, b reflect.Value) bool
+	switch kind {
+	case kindString:
+		less = func(a, b reflect.Value) bool { return a.String() < b.String() }
+	case kindInt:
+		less = func(a, b reflect.Value) bool { return a.Int() < b.Int() }
+	case kindUint:
+		less = func(a, b reflect.Value) bool { return a.Uint() < b.Uint() }
+	case kindFloat:
+		less = func(a, b reflect.Value) bool { return a.Float() < b.Float() }
+	default:
+		sortKeys(keys, nil)
+		return
+	}
+	values := make([]reflect.Value, len(keys))
+	for i, key := range keys {
+		values[i] = reflect.ValueOf(key)
+	}
+	sort.Sort(reflectValueSorter{keys: keys, values: values, less: less})
+}
+
+// reflectValueSorter sorts `keys` along with their reflect values `values` by function `less`.
+type reflectValueSorter struct {
+	keys   []interface{}
+	values []reflect.Value
+	less   func(a, b reflect.Value) bool
+}
+
+func (s reflectValueSorter) Len() int           { return len(s.keys) }
+func (s reflectValueSorter) Less(i, j int) bool { return s.less(s.values[i], s.values[j]) }
+func (s reflectValueSorter) Swap(i, j int) {
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+	s.values[i], s.values[j] = s.values[j], s.values[i]
+}
+
+// sortKeysBySeed sorts `keys` in place in a deterministic pseudo-random order of `seed`,
+// which is the order of the hashes of the keys mixed with `seed`.
+func sortKeysBySeed(keys []interface{}, seed int64) {
+	// The keys are sorted by fmt.Sprint first so that the keys having the same hash are in stable order.
+	sortKeys(keys, nil)
+	var (
+		mixedSeed = mixUint64(uint64(seed))
+		hashes    = make(map[interface{}]uint64, len(keys))
+	)
+	for _, key := range keys {
+		hashes[key] = mixUint64(hashKey(key) ^ mixedSeed)
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return hashes[keys[i]] < hashes[keys[j]]
+	})
+}
+
+// assignValue converts `value` to the type of `dst` and assigns it to `dst`.
+// The numeric and string values are converted to each other, and so are the []byte values as strings.
+// The bool value is converted to string and numeric types as true is 1, and the string to bool type.
+// The nil value assigns the zero value, and it returns an error if `value` cannot be converted,
+// or overflows the type of `dst`.
+func assignValue(dst reflect.Value, value interface{}) error {
+	if value == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Type().AssignableTo(dst.Type()) {
+		dst.Set(rv)
+		return nil
+	}
+	if dst.Kind() == reflect.Ptr {
+		elem := reflect.New(dst.Type().Elem())
+		if err := assignValue(elem.Elem(), value); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+	}
+	if s, ok := value.([]byte); ok {
+		rv = reflect.ValueOf(string(s))
+	}
+	var ok bool
+	switch dst.Kind() {
+	case reflect.String:
+		switch rv.Kind() {
+		case reflect.String, reflect.Bool,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+			reflect.Float32, reflect.Float64:
+			dst.SetString(fmt.Sprint(rv.Interface()))
+			ok = true
+		}
+
+	case reflect.Bool:
+		switch rv.Kind() {
+		case reflect.Bool:
+			dst.SetBool(rv.Bool())
+			ok = true
+		case reflect.String:
+			var (
+				b   bool
+				err error
+			)
+			if b, err = strconv.ParseBool(strings.TrimSpace(rv.String())); err == nil {
+				dst.SetBool(b)
+				ok = true
+			}
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		switch rv.Kind() {
+		case reflect.Bool:
+			if rv.Bool() {
+				i = 1
+			}
+			ok = true
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			i, ok = rv.Int(), true
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			i, ok = int64(rv.Uint()), rv.Uint() <= math.MaxInt64
+		case reflect.String:
+			var err error
+			if i, err = strconv.ParseInt(strings.TrimSpace(rv.String()), 10, 64); err == nil {
+				ok = true
+				break
+			}
+			fallthrough
+		case reflect.Float32, reflect.Float64:
+			var f float64
+			if f, ok = toFloat64(rv.Interface()); ok {
+				ok = f == math.Trunc(f) && f >= math.MinInt64 && f < math.MaxInt64
+				i = int64(f)
+			}
+		}
+		if ok = ok && !dst.OverflowInt(i); ok {
+			dst.SetInt(i)
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var u uint64
+		switch rv.Kind() {
+		case reflect.Bool:
+			if rv.Bool() {
+				u = 1
+			}
+			ok = true
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			u, ok = uint64(rv.Int()), rv.Int() >= 0
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			u, ok = rv.Uint(), true
+		case reflect.String:
+			var err error
+			if u, err = strconv.ParseUint(strings.TrimSpace(rv.String()), 10, 64); err == nil {
+				ok = true
+				break
+			}
+			fallthrough
+		case reflect.Float32, reflect.Float64:
+			var f float64
+			if f, ok = toFloat64(rv.Interface()); ok {
+				ok = f == math.Trunc(f) && f >= 0 && f < math.MaxUint64
+				u = uint64(f)
+			}
+		}
+		if ok = ok && !dst.OverflowUint(u); ok {
+			dst.SetUint(u)
+		}
+
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if rv.Kind() == reflect.Bool {
+			if rv.Bool() {
+				f = 1
+			}
+			ok = true
+		} else {
+			f, ok = toFloat64(rv.Interface())
+		}
+		if ok {
+			if ok = !dst.OverflowFloat(f); ok {
+				dst.SetFloat(f)
+			}
+		}
+
+	default:
+		// The values of the same kind, like the named types, are converted directly.
+		if rv.Kind() == dst.Kind() && rv.Type().ConvertibleTo(dst.Type()) {
+			dst.Set(rv.Convert(dst.Type()))
+			ok = true
+		}
+	}
+	if !ok {
+		return gerror.NewCodef(
+			gcode.CodeInvalidParameter, `value "%v" of type "%T" cannot be converted to type "%s"`, value, value, dst.Type(),
+		)
+	}
+	return nil
+}
+
+// valuesEqual checks whether `a` and `b` are deeply equal, which is the same as reflect.DeepEqual,
+// but it compares the values of the basic types, like int and string, using == without reflection.
+// The other types, including pointers, are compared by reflect.DeepEqual, which compares the pointed values.
+func valuesEqual(a, b interface{}) bool {
+	switch v := a.(type) {
+	case nil:
+		return b == nil
+	case string:
+		w, ok := b.(string)
+		return ok && v == w
+	case int:
+		w, ok := b.(int)
+		return ok && v == w
+	case int64:
+		w, ok := b.(int64)
+		return ok && v == w
+	case float64:
+		w, ok := b.(float64)
+		return ok && v == w
+	case bool:
+		w, ok := b.(bool)
+		return ok && v == w
+	case int8, int16, int32, uint, uint8, uint16, uint32, uint64, uintptr, float32, complex64, complex128:
+		// The == on interfaces compares both the dynamic types and the values.
+		return a == b
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
+
+// isHashable checks whether `rv` can be used as the key of map without panicking.
+// Unlike reflect.Type.Comparable, it checks the dynamic values of the interfaces in `rv`.
+func isHashable(rv reflect.Value) bool {
+	switch rv.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Interface:
+		return rv.IsNil() || isHashable(rv.Elem())
+	case reflect.Struct:
+		for i := 0; i < rv.NumField(); i++ {
+			if !isHashable(rv.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if !isHashable(rv.Index(i)) {
+				return false
+			}
+		}
+		return rv.Type().Comparable()
+	default:
+		return rv.Type().Comparable()
+	}
+}
+
+// estimateSize returns the estimated memory size in bytes of `rv`, which is the size of its type along with
+// the size of the data it refers to. The `visited` records the visited pointers, maps and slices on the
+// current path, which prevents endless recursion for the cyclic references.
+func estimateSize(rv reflect.Value, visited map[uintptr]struct{}) int64 {
+	return int64(rv.Type().Size()) + estimateReferredSize(rv, visited)
+}
+
+// estimateReferredSize returns the estimated memory size in bytes of the data `rv` refers to,
+// excluding the size of the type of `rv`.
+func estimateReferredSize(rv reflect.Value, visited map[uintptr]struct{}) (size int64) {
+	switch rv.Kind() {
+	case reflect.String:
+		return int64(rv.Len())
+
+	case reflect.Interface:
+		if rv.IsNil() {
+			return 0
+		}
+		return estimateSize(rv.Elem(), visited)
+
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return 0
+		}
+		if _, ok := visited[rv.Pointer()]; ok {
+			return 0
+		}
+		visited[rv.Pointer()] = struct{}{}
+		defer delete(visited, rv.Pointer())
+		return estimateSize(rv.Elem(), visited)
+
+	case reflect.Map:
+		if rv.IsNil() {
+			return 0
+		}
+		if _, ok := visited[rv.Pointer()]; ok {
+			return 0
+		}
+		visited[rv.Pointer()] = struct{}{}
+		defer delete(visited, rv.Pointer())
+		iter := rv.MapRange()
+		for iter.Next() {
+			size += estimateSize(iter.Key(), visited) + estimateSize(iter.Value(), visited)
+		}
+		return size
+
+	case reflect.Slice:
+		if rv.IsNil() {
+			return 0
+		}
+		if _, ok := visited[rv.Pointer()]; ok {
+			return 0
+		}
+		visited[rv.Pointer()] = struct{}{}
+		defer delete(visited, rv.Pointer())
+		size = int64(rv.Cap()) * int64(rv.Type().Elem().Size())
+		for i := 0; i < rv.Len(); i++ {
+			size += estimateReferredSize(rv.Index(i), visited)
+		}
+		return size
+
+	case reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			size += estimateReferredSize(rv.Index(i), visited)
+		}
+		return size
+
+	case reflect.Struct:
+		for i := 0; i < rv.NumField(); i++ {
+			size += estimateReferredSize(rv.Field(i), visited)
+		}
+		return size
+
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,289 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with gm file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gmap
+
+import (
+	"github.com/gogf/gf/v2/internal/json"
+	"github.com/gogf/gf/v2/internal/rwmutex"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+// GMap wraps map type `map[K]V` and provides more map features with type parameters.
+// It mirrors the API of Map, but the keys and values are typed, which needs no type assertion.
+type GMap[K comparable, V any] struct {
+	mu   rwmutex.RWMutex
+	data map[K]V
+}
+
+// NewGMap creates and returns an empty generic hash map.
+// The parameter `safe` is used to specify whether using map in concurrent-safety,
+// which is false in default.
+func NewGMap[K comparable, V any](safe ...bool) *GMap[K, V] {
+	return &GMap[K, V]{
+		mu:   rwmutex.Create(safe...),
+		data: make(map[K]V),
+	}
+}
+
+// NewGMapFrom creates and returns a generic hash map from given map `data`.
+// Note that, the param `data` map will be set as the underlying data map(no deep copy),
+// there might be some concurrent-safe issues when changing the map outside.
+func NewGMapFrom[K comparable, V any](data map[K]V, safe ...bool) *GMap[K, V] {
+	return &GMap[K, V]{
+		mu:   rwmutex.Create(safe...),
+		data: data,
+	}
+}
+
+// Iterator iterates the hash map readonly with custom callback function `f`.
+// If `f` returns true, then it continues iterating; or false to stop.
+func (m *GMap[K, V]) Iterator(f func(k K, v V) bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for k, v := range m.data {
+		if !f(k, v) {
+			break
+		}
+	}
+}
+
+// Clone returns a new hash map with copy of current map data.
+func (m *GMap[K, V]) Clone(safe ...bool) *GMap[K, V] {
+	return NewGMapFrom(m.MapCopy(), safe...)
+}
+
+// Map returns the underlying data map.
+// Note that, if it's in concurrent-safe usage, it returns a copy of underlying data,
+// or else a pointer to the underlying data.
+func (m *GMap[K, V]) Map() map[K]V {
+	if !m.mu.IsSafe() {
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+		return m.data
+	}
+	return m.MapCopy()
+}
+
+// MapCopy returns a shallow copy of the underlying data of the hash map.
+func (m *GMap[K, V]) MapCopy() map[K]V {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	data := make(map[K]V, len(m.data))
+	for k, v := range m.data {
+		data[k] = v
+	}
+	return data
+}
+
+// Set sets key-value to the hash map.
+func (m *GMap[K, V]) Set(key K, value V) {
+	m.mu.Lock()
+	if m.data == nil {
+		m.data = make(map[K]V)
+	}
+	m.data[key] = value
+	m.mu.Unlock()
+}
+
+// Sets batch sets key-values to the hash map.
+func (m *GMap[K, V]) Sets(data map[K]V) {
+	m.mu.Lock()
+	if m.data == nil {
+		m.data = make(map[K]V, len(data))
+	}
+	for k, v := range data {
+		m.data[k] = v
+	}
+	m.mu.Unlock()
+}
+
+// Search searches the map with given `key`.
+// Second return parameter `found` is true if key was found, otherwise false.
+func (m *GMap[K, V]) Search(key K) (value V, found bool) {
+	m.mu.RLock()
+	value, found = m.data[key]
+	m.mu.RUnlock()
+	return
+}
+
+// Get returns the value by given `key`, or the zero value of V if the `key` does not exist.
+func (m *GMap[K, V]) Get(key K) (value V) {
+	value, _ = m.Search(key)
+	return
+}
+
+// doSetWithLockCheck checks whether value of the key exists with mutex.Lock,
+// if not exists, set value returned by `f` to the map with given `key`,
+// or else just return the existing value.
+func (m *GMap[K, V]) doSetWithLockCheck(key K, f func() V) V {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.data == nil {
+		m.data = make(map[K]V)
+	}
+	if v, ok := m.data[key]; ok {
+		return v
+	}
+	value := f()
+	m.data[key] = value
+	return value
+}
+
+// GetOrSet returns the value by key,
+// or sets value with given `value` if it does not exist and then returns this value.
+func (m *GMap[K, V]) GetOrSet(key K, value V) V {
+	if v, ok := m.Search(key); ok {
+		return v
+	}
+	return m.doSetWithLockCheck(key, func() V { return value })
+}
+
+// GetOrSetFunc returns the value by key,
+// or sets value with returned value of callback function `f` if it does not exist
+// and then returns this value.
+func (m *GMap[K, V]) GetOrSetFunc(key K, f func() V) V {
+	if v, ok := m.Search(key); ok {
+		return v
+	}
+	value := f()
+	return m.doSetWithLockCheck(key, func() V { return value })
+}
+
+// GetOrSetFuncLock returns the value by key,
+// or sets value with returned value of callback function `f` if it does not exist
+// and then returns this value.
+//
+// GetOrSetFuncLock differs with GetOrSetFunc function is that it executes function `f`
+// with mutex.Lock of the hash map.
+func (m *GMap[K, V]) GetOrSetFuncLock(key K, f func() V) V {
+	if v, ok := m.Search(key); ok {
+		return v
+	}
+	return m.doSetWithLockCheck(key, f)
+}
+
+// SetIfNotExist sets `value` to the map if the `key` does not exist, and then returns true.
+// It returns false if `key` exists, and `value` would be ignored.
+func (m *GMap[K, V]) SetIfNotExist(key K, value V) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.data == nil {
+		m.data = make(map[K]V)
+	}
+	if _, ok := m.data[key]; ok {
+		return false
+	}
+	m.data[key] = value
+	return true
+}
+
+// Remove deletes value from map by given `key`, and return this deleted value.
+func (m *GMap[K, V]) Remove(key K) (value V) {
+	m.mu.Lock()
+	if v, ok := m.data[key]; ok {
+		value = v
+		delete(m.data, key)
+	}
+	m.mu.Unlock()
+	return
+}
+
+// Removes batch deletes values of the map by keys.
+func (m *GMap[K, V]) Removes(keys []K) {
+	m.mu.Lock()
+	for _, key := range keys {
+		delete(m.data, key)
+	}
+	m.mu.Unlock()
+}
+
+// Keys returns all keys of the map as a slice.
+func (m *GMap[K, V]) Keys() []K {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	keys := make([]K, 0, len(m.data))
+	for key := range m.data {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Values returns all values of the map as a slice.
+func (m *GMap[K, V]) Values() []V {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	values := make([]V, 0, len(m.data))
+	for _, value := range m.data {
+		values = append(values, value)
+	}
+	return values
+}
+
+// Contains checks whether a key exists.
+// It returns true if the `key` exists, or else false.
+func (m *GMap[K, V]) Contains(key K) bool {
+	_, found := m.Search(key)
+	return found
+}
+
+// Size returns the size of the map.
+func (m *GMap[K, V]) Size() int {
+	m.mu.RLock()
+	length := len(m.data)
+	m.mu.RUnlock()
+	return length
+}
+
+// IsEmpty checks whether the map is empty.
+// It returns true if map is empty, or else false.
+func (m *GMap[K, V]) IsEmpty() bool {
+	return m.Size() == 0
+}
+
+// Clear deletes all data of the map, it will remake a new underlying data map.
+func (m *GMap[K, V]) Clear() {
+	m.mu.Lock()
+	m.data = make(map[K]V)
+	m.mu.Unlock()
+}
+
+// LockFunc locks writing with given callback function `f` within RWMutex.Lock.
+func (m *GMap[K, V]) LockFunc(f func(m map[K]V)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.data == nil {
+		m.data = make(map[K]V)
+	}
+	f(m.data)
+}
+
+// RLockFunc locks reading with given callback function `f` within RWMutex.RLock.
+func (m *GMap[K, V]) RLockFunc(f func(m map[K]V)) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	f(m.data)
+}
+
+// String returns the map as a string.
+func (m *GMap[K, V]) String() string {
+	if m == nil {
+		return ""
+	}
+	b, _ := m.MarshalJSON()
+	return string(b)
+}
+
+// MarshalJSON implements the interface MarshalJSON for json.Marshal.
+func (m *GMap[K, V]) MarshalJSON() ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	data := make(map[string]interface{}, len(m.data))
+	for k, v := range m.data {
+		data[gconv.String(k)] = v
+	}
+	return json.Marshal(data)
+}
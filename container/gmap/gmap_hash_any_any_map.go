@@ -7,19 +7,71 @@
 package gmap
 
 import (
+	"bytes"
+	"container/heap"
+	"context"
+	"encoding/gob"
+	"math"
+	"math/rand"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/container/gtype"
 	"github.com/gogf/gf/v2/container/gvar"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
 	"github.com/gogf/gf/v2/internal/deepcopy"
 	"github.com/gogf/gf/v2/internal/empty"
 	"github.com/gogf/gf/v2/internal/json"
 	"github.com/gogf/gf/v2/internal/rwmutex"
 	"github.com/gogf/gf/v2/util/gconv"
-	"reflect"
+	"github.com/gogf/gf/v2/util/grand"
 )
 
 // AnyAnyMap wraps map type `map[interface{}]interface{}` and provides more map features.
 type AnyAnyMap struct {
-	mu   rwmutex.RWMutex
-	data map[interface{}]interface{}
+	mu      rwmutex.RWMutex
+	data    map[interface{}]interface{}
+	loading map[interface{}]*gAnyAnyMapLoadCall // loading holds the in-flight calls of GetOrLoad by key.
+
+	setHandlers    []func(key, oldValue, newValue interface{}) // setHandlers are called after each key is set.
+	removeHandlers []func(key, value interface{})              // removeHandlers are called after each key is deleted.
+	watchers       []chan Event                                // watchers are the channels of Watch receiving the changes.
+	watchDropped   gtype.Int                                   // watchDropped is the count of events dropped by Watch.
+
+	stats  gtype.Interface // stats holds the *gAnyAnyMapStats counting the usage of the map if enabled, see EnableStats.
+	frozen gtype.Bool      // frozen marks the map as read-only, see Freeze.
+	shared bool            // shared marks the data map is shared with other maps by COWClone, which is copied before changing.
+
+	version gtype.Int64 // version is increased before each change of the data map, which invalidates the MapView.
+
+	changes *gAnyAnyMapChanges // changes records the changed keys if it is not nil, see TrackChanges.
+}
+
+// gAnyAnyMapChanges records the keys of AnyAnyMap changed since last flushing, see TrackChanges.
+type gAnyAnyMapChanges struct {
+	changed map[interface{}]struct{}
+	removed map[interface{}]struct{}
+}
+
+// gAnyAnyMapLoadCall is an in-flight or completed loader call of GetOrLoad.
+type gAnyAnyMapLoadCall struct {
+	wg    sync.WaitGroup
+	value interface{}
+	err   error
+}
+
+// gAnyAnyMapStats holds the usage counters of AnyAnyMap, which are changed atomically.
+type gAnyAnyMapStats struct {
+	sets    gtype.Int64
+	gets    gtype.Int64
+	hits    gtype.Int64
+	misses  gtype.Int64
+	removes gtype.Int64
 }
 
 // NewAnyAnyMap creates and returns an empty hash map.
@@ -44,9 +96,18 @@ func NewAnyAnyMapFrom(data map[interface{}]interface{}, safe ...bool) *AnyAnyMap
 
 // Iterator iterates the hash map readonly with custom callback function `f`.
 // If `f` returns true, then it continues iterating; or false to stop.
+// The iteration order is random, unless it is made deterministic by SetIterationSeed.
 func (m *AnyAnyMap) Iterator(f func(k interface{}, v interface{}) bool) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
+	if seed, ok := getIterationSeed(); ok {
+		for _, k := range m.doSeededKeys(seed) {
+			if !f(k, m.data[k]) {
+				break
+			}
+		}
+		return
+	}
 	for k, v := range m.data {
 		if !f(k, v) {
 			break
@@ -54,11 +115,117 @@ func (m *AnyAnyMap) Iterator(f func(k interface{}, v interface{}) bool) {
 	}
 }
 
+// IteratorE iterates the hash map readonly with custom callback function `f`.
+// It stops iterating and returns the error if `f` returns a non-nil error, or else it returns nil.
+func (m *AnyAnyMap) IteratorE(f func(k interface{}, v interface{}) error) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if seed, ok := getIterationSeed(); ok {
+		for _, k := range m.doSeededKeys(seed) {
+			if err := f(k, m.data[k]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for k, v := range m.data {
+		if err := f(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IteratorSnapshot iterates a snapshot of the hash map readonly with custom callback function `f`.
+// If `f` returns true, then it continues iterating; or false to stop.
+//
+// IteratorSnapshot differs with Iterator function is that it copies the keys and values
+// within a brief RWMutex.RLock and calls `f` after the lock is released, so that slow `f`
+// does not block the writers. Note that the snapshot might be stale if the map is changed
+// concurrently during iterating.
+func (m *AnyAnyMap) IteratorSnapshot(f func(k interface{}, v interface{}) bool) {
+	m.mu.RLock()
+	var (
+		keys   = make([]interface{}, 0, len(m.data))
+		values = make([]interface{}, 0, len(m.data))
+	)
+	if seed, ok := getIterationSeed(); ok {
+		keys = m.doSeededKeys(seed)
+		for _, k := range keys {
+			values = append(values, m.data[k])
+		}
+	} else {
+		for k, v := range m.data {
+			keys = append(keys, k)
+			values = append(values, v)
+		}
+	}
+	m.mu.RUnlock()
+	for i, k := range keys {
+		if !f(k, values[i]) {
+			break
+		}
+	}
+}
+
 // Clone returns a new hash map with copy of current map data.
 func (m *AnyAnyMap) Clone(safe ...bool) *AnyAnyMap {
 	return NewFrom(m.MapCopy(), safe...)
 }
 
+// COWClone returns a new hash map sharing the underlying data map with current map, which is copy-on-write.
+// It is nearly free as no data is copied, and the data map is copied lazily by the first change on either
+// side of the current map and the returned map, so that changes on one side are not visible on the other side.
+//
+// Note that the values are shared just like Clone, and changing the data map returned by Map directly
+// in non-concurrent-safe usage breaks the isolation.
+func (m *AnyAnyMap) COWClone(safe ...bool) *AnyAnyMap {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.data != nil {
+		m.shared = true
+	}
+	return &AnyAnyMap{
+		mu:     rwmutex.Create(safe...),
+		data:   m.data,
+		shared: m.shared,
+	}
+}
+
+// detach copies the underlying data map without mutex if it is shared by COWClone,
+// so that the data map can be changed exclusively. It should be called before changing the data map,
+// and it increases the version of the map.
+func (m *AnyAnyMap) detach() {
+	m.version.Add(1)
+	if !m.shared {
+		return
+	}
+	data := make(map[interface{}]interface{}, len(m.data))
+	for k, v := range m.data {
+		data[k] = v
+	}
+	m.data = data
+	m.shared = false
+}
+
+// doReplace replaces the underlying data map with `data` without mutex, and increases the version of the map.
+// The keys of the old and new data maps are recorded as deleted and set if tracking changes is enabled.
+func (m *AnyAnyMap) doReplace(data map[interface{}]interface{}) {
+	m.version.Add(1)
+	if m.changes != nil {
+		for key := range m.data {
+			if _, ok := data[key]; !ok {
+				m.changes.record(key, true)
+			}
+		}
+		for key := range data {
+			m.changes.record(key, false)
+		}
+	}
+	m.data = data
+	m.shared = false
+}
+
 // Map returns the underlying data map.
 // Note that, if it's in concurrent-safe usage, it returns a copy of underlying data,
 // or else a pointer to the underlying data.
@@ -97,55 +264,436 @@ func (m *AnyAnyMap) MapStrAny() map[string]interface{} {
 	return data
 }
 
+// Scan assigns the values of the map to the exported fields of struct `pointer` within one RWMutex.RLock.
+// Each field reads the value of the string key named by its "gmap" tag, or its "json" tag,
+// or else its field name. The field having tag "-" and the fields of which the key does not exist are
+// left unchanged. The fields of embedded structs are scanned as the fields of the outer struct.
+//
+// The value is converted to the type of field if it is numeric, string or bool, and it returns an error
+// if the value cannot be converted, or overflows the type of field.
+func (m *AnyAnyMap) Scan(pointer interface{}) error {
+	rv := reflect.ValueOf(pointer)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return gerror.NewCodef(gcode.CodeInvalidParameter, `pointer should be type of *struct, but got "%T"`, pointer)
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.doScan(rv.Elem())
+}
+
+// doScan assigns the values of the map to the fields of struct value `rv` without lock.
+func (m *AnyAnyMap) doScan(rv reflect.Value) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if err := m.doScan(rv.Field(i)); err != nil {
+				return err
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Name
+		for _, tagName := range []string{"gmap", "json"} {
+			if tag := strings.Split(field.Tag.Get(tagName), ",")[0]; tag != "" {
+				name = tag
+				break
+			}
+		}
+		if name == "-" {
+			continue
+		}
+		value, ok := m.data[name]
+		if !ok {
+			continue
+		}
+		if err := assignValue(rv.Field(i), value); err != nil {
+			return gerror.WrapCodef(gcode.CodeInvalidParameter, err, `scanning key "%s" to field "%s" failed`, name, field.Name)
+		}
+	}
+	return nil
+}
+
+// ToStrAnyMap converts the map to a new StrAnyMap with the same concurrent-safety,
+// in which the keys are converted to string by gconv.String.
+// Note that the keys having the same string representation overwrite each other.
+func (m *AnyAnyMap) ToStrAnyMap() *StrAnyMap {
+	return NewStrAnyMapFrom(m.MapStrAny(), m.mu.IsSafe())
+}
+
+// ToIntIntMapE converts the map to a new IntIntMap with the same concurrent-safety.
+// The keys and values should be integers or whole numbers of float or numeric string that fit in int,
+// or else it returns an error for the first key or value that cannot be converted.
+func (m *AnyAnyMap) ToIntIntMapE() (*IntIntMap, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	data := make(map[int]int, len(m.data))
+	for k, v := range m.data {
+		intKey, ok := toInt(k)
+		if !ok {
+			return nil, gerror.NewCodef(gcode.CodeInvalidParameter, `key "%v" cannot be converted to int`, k)
+		}
+		intValue, ok := toInt(v)
+		if !ok {
+			return nil, gerror.NewCodef(
+				gcode.CodeInvalidParameter, `value "%v" of key "%v" cannot be converted to int`, v, k,
+			)
+		}
+		data[intKey] = intValue
+	}
+	return NewIntIntMapFrom(data, m.mu.IsSafe()), nil
+}
+
 // FilterEmpty deletes all key-value pair of which the value is empty.
 // Values like: 0, nil, false, "", len(slice/map/chan) == 0 are considered empty.
 func (m *AnyAnyMap) FilterEmpty() {
+	m.checkFrozen()
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	for k, v := range m.data {
 		if empty.IsEmpty(v) {
-			delete(m.data, k)
+			m.doRemove(k)
 		}
 	}
 }
 
 // FilterNil deletes all key-value pair of which the value is nil.
 func (m *AnyAnyMap) FilterNil() {
+	m.checkFrozen()
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	for k, v := range m.data {
 		if empty.IsNil(v) {
-			delete(m.data, k)
+			m.doRemove(k)
 		}
 	}
 }
 
 // Set sets key-value to the hash map.
 func (m *AnyAnyMap) Set(key interface{}, value interface{}) {
+	m.checkFrozen()
 	m.mu.Lock()
 	if m.data == nil {
 		m.data = make(map[interface{}]interface{})
 	}
-	m.data[key] = value
+	m.doSet(key, value)
 	m.mu.Unlock()
 }
 
+// SetSafe sets key-value to the hash map like Set, but it returns an error instead of panicking
+// if `key` is not hashable, which is a slice, map or func, or a struct or array containing them.
+// It is used for setting the dynamic keys that might be unhashable.
+func (m *AnyAnyMap) SetSafe(key interface{}, value interface{}) error {
+	if !isHashable(reflect.ValueOf(key)) {
+		return gerror.NewCodef(
+			gcode.CodeInvalidParameter,
+			`key "%v" of type "%T" is not hashable, as it is or contains slice, map or func`,
+			key, key,
+		)
+	}
+	m.Set(key, value)
+	return nil
+}
+
 // Sets batch sets key-values to the hash map.
 func (m *AnyAnyMap) Sets(data map[interface{}]interface{}) {
+	m.checkFrozen()
 	m.mu.Lock()
-	if m.data == nil {
-		m.data = data
+	if m.data == nil && len(m.setHandlers) == 0 && len(m.watchers) == 0 {
+		m.doReplace(data)
 	} else {
+		if m.data == nil {
+			m.data = make(map[interface{}]interface{}, len(data))
+		}
 		for k, v := range data {
-			m.data[k] = v
+			m.doSet(k, v)
 		}
 	}
 	m.mu.Unlock()
 }
 
+// SetReturning batch sets key-values to the hash map within one RWMutex.Lock,
+// and returns the previous values of the keys in `data`.
+// The previous value of a key is nil in the returned map if the key did not exist.
+func (m *AnyAnyMap) SetReturning(data map[interface{}]interface{}) map[interface{}]interface{} {
+	m.checkFrozen()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.data == nil {
+		m.data = make(map[interface{}]interface{})
+	}
+	previous := make(map[interface{}]interface{}, len(data))
+	for k, v := range data {
+		previous[k] = m.data[k]
+		m.doSet(k, v)
+	}
+	return previous
+}
+
+// OnSet adds handler `f` which is called with the key, old value and new value after each key is set,
+// in which the old value is nil if the key did not exist. The nil `f` is ignored.
+//
+// OnSet handlers are called by the methods setting items of the map like Set, Sets, GetOrSet, Merge, etc.
+// Note that they are not called by Clear, Replace, Flip, the LockFunc family and unmarshalling, which
+// replace or change the underlying data map as a whole.
+//
+// The handlers are called within RWMutex.Lock in the same goroutine, so they should not call
+// any method of the map, or else it would deadlock.
+func (m *AnyAnyMap) OnSet(f func(key, oldValue, newValue interface{})) {
+	if f == nil {
+		return
+	}
+	m.mu.Lock()
+	m.setHandlers = append(m.setHandlers, f)
+	m.mu.Unlock()
+}
+
+// OnRemove adds handler `f` which is called with the key and deleted value after each key is deleted.
+// The nil `f` is ignored.
+//
+// OnRemove handlers are called by the methods deleting items of the map like Remove, Removes, Pop, etc.
+// Note that they are not called by Clear, Replace, Flip and the LockFunc family.
+//
+// The handlers are called within RWMutex.Lock in the same goroutine, so they should not call
+// any method of the map, or else it would deadlock.
+func (m *AnyAnyMap) OnRemove(f func(key, value interface{})) {
+	if f == nil {
+		return
+	}
+	m.mu.Lock()
+	m.removeHandlers = append(m.removeHandlers, f)
+	m.mu.Unlock()
+}
+
+// Watch subscribes the changes of the map, and returns a channel receiving the change events and
+// a function `cancel` which unsubscribes and closes the channel. The parameter `buffer` is the buffer
+// size of the channel.
+//
+// The events are sent after each key is set or deleted, just like the OnSet and OnRemove handlers,
+// and after the map is cleared by Clear. The events are sent without blocking the changing of the map,
+// so the event is dropped if the buffer of the channel is full, and the count of the dropped events
+// can be retrieved by WatchDropped.
+func (m *AnyAnyMap) Watch(buffer int) (events <-chan Event, cancel func()) {
+	if buffer < 0 {
+		buffer = 0
+	}
+	ch := make(chan Event, buffer)
+	m.mu.Lock()
+	m.watchers = append(m.watchers, ch)
+	m.mu.Unlock()
+	var once sync.Once
+	cancel = func() {
+		once.Do(func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			for i, watcher := range m.watchers {
+				if watcher == ch {
+					m.watchers = append(m.watchers[:i:i], m.watchers[i+1:]...)
+					break
+				}
+			}
+			close(ch)
+		})
+	}
+	return ch, cancel
+}
+
+// WatchDropped returns the total count of events dropped by Watch because the channel buffer is full.
+func (m *AnyAnyMap) WatchDropped() int {
+	return m.watchDropped.Val()
+}
+
+// notifyWatchers sends `event` to the channels of Watch without blocking.
+// It should be called within RWMutex.Lock.
+func (m *AnyAnyMap) notifyWatchers(event Event) {
+	for _, ch := range m.watchers {
+		select {
+		case ch <- event:
+		default:
+			m.watchDropped.Add(1)
+		}
+	}
+}
+
+// EnableStats enables or disables counting the usage statistics of the map, which is disabled in default,
+// as the counters are changed atomically on every reading and writing of the map.
+// Disabling it discards the counters, and enabling it again counts from zero.
+func (m *AnyAnyMap) EnableStats(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch {
+	case !enabled:
+		m.stats.Set((*gAnyAnyMapStats)(nil))
+	case m.getStats() == nil:
+		m.stats.Set(&gAnyAnyMapStats{})
+	}
+}
+
+// Stats returns the usage statistics of the map, which are counted atomically without the lock of the map.
+// The counters are all zero unless the statistics are enabled by EnableStats.
+// Note that the counters are not changed by the LockFunc family, which change the data map directly.
+func (m *AnyAnyMap) Stats() MapStats {
+	stats := MapStats{Size: m.Size()}
+	if s := m.getStats(); s != nil {
+		stats.Sets = s.sets.Val()
+		stats.Gets = s.gets.Val()
+		stats.Hits = s.hits.Val()
+		stats.Misses = s.misses.Val()
+		stats.Removes = s.removes.Val()
+	}
+	return stats
+}
+
+// ResetStats resets all the usage counters of the map to zero.
+func (m *AnyAnyMap) ResetStats() {
+	if s := m.getStats(); s != nil {
+		s.sets.Set(0)
+		s.gets.Set(0)
+		s.hits.Set(0)
+		s.misses.Set(0)
+		s.removes.Set(0)
+	}
+}
+
+// getStats returns the usage counters of the map, or nil if the statistics are not enabled.
+func (m *AnyAnyMap) getStats() *gAnyAnyMapStats {
+	stats, _ := m.stats.Val().(*gAnyAnyMapStats)
+	return stats
+}
+
+// TrackChanges enables or disables tracking the changed keys of the map, which is disabled in default.
+// When it is enabled, the map records the keys set or deleted since the last FlushChanges,
+// which is used for persisting the map incrementally. Disabling it discards the recorded keys.
+//
+// Note that the changes by the LockFunc family, which change the data map directly, are not tracked.
+func (m *AnyAnyMap) TrackChanges(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch {
+	case !enabled:
+		m.changes = nil
+	case m.changes == nil:
+		m.changes = &gAnyAnyMapChanges{
+			changed: make(map[interface{}]struct{}),
+			removed: make(map[interface{}]struct{}),
+		}
+	}
+}
+
+// FlushChanges returns the keys set and the keys deleted since the last FlushChanges within one RWMutex.Lock,
+// and then clears the recorded keys. A key is in either `changed` or `removed` by its last change,
+// and the keys are in random order. It returns nil if tracking is not enabled by TrackChanges.
+func (m *AnyAnyMap) FlushChanges() (changed, removed []interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.changes == nil {
+		return nil, nil
+	}
+	for key := range m.changes.changed {
+		changed = append(changed, key)
+	}
+	for key := range m.changes.removed {
+		removed = append(removed, key)
+	}
+	m.changes.changed = make(map[interface{}]struct{})
+	m.changes.removed = make(map[interface{}]struct{})
+	return
+}
+
+// record records `key` as set, or deleted if `removed` is true.
+func (c *gAnyAnyMapChanges) record(key interface{}, removed bool) {
+	if removed {
+		delete(c.changed, key)
+		c.removed[key] = struct{}{}
+	} else {
+		delete(c.removed, key)
+		c.changed[key] = struct{}{}
+	}
+}
+
+// recordLookup counts a hit if `hit` is true, or else a miss.
+func (m *AnyAnyMap) recordLookup(hit bool) {
+	stats := m.getStats()
+	switch {
+	case stats == nil:
+	case hit:
+		stats.hits.Add(1)
+	default:
+		stats.misses.Add(1)
+	}
+}
+
+// Freeze makes the map read-only, after which all the methods changing the map panic,
+// while the reading methods continue working normally. It cannot be undone.
+// Note that the map returned by Clone is not frozen.
+func (m *AnyAnyMap) Freeze() {
+	m.frozen.Set(true)
+}
+
+// IsFrozen checks whether the map is frozen by Freeze.
+func (m *AnyAnyMap) IsFrozen() bool {
+	return m.frozen.Val()
+}
+
+// checkFrozen panics if the map is frozen.
+// It should be called before changing the map and not within the lock unless the lock is released by defer.
+func (m *AnyAnyMap) checkFrozen() {
+	if m.frozen.Val() {
+		panic(gerror.NewCode(gcode.CodeInvalidOperation, `cannot change the frozen map`))
+	}
+}
+
+// doSet sets key-value to the map without mutex, and calls the OnSet handlers.
+// The underlying data map should be initialized before calling it.
+func (m *AnyAnyMap) doSet(key interface{}, value interface{}) {
+	if stats := m.getStats(); stats != nil {
+		stats.sets.Add(1)
+	}
+	m.detach()
+	if m.changes != nil {
+		m.changes.record(key, false)
+	}
+	if len(m.setHandlers) == 0 && len(m.watchers) == 0 {
+		m.data[key] = value
+		return
+	}
+	oldValue := m.data[key]
+	m.data[key] = value
+	for _, f := range m.setHandlers {
+		f(key, oldValue, value)
+	}
+	m.notifyWatchers(Event{Type: EventSet, Key: key, Old: oldValue, New: value})
+}
+
+// doRemove deletes value from map by given `key` without mutex, and calls the OnRemove handlers
+// if the `key` exists. It returns the deleted value and whether the `key` exists.
+func (m *AnyAnyMap) doRemove(key interface{}) (value interface{}, found bool) {
+	if value, found = m.data[key]; !found {
+		return
+	}
+	if stats := m.getStats(); stats != nil {
+		stats.removes.Add(1)
+	}
+	m.detach()
+	delete(m.data, key)
+	if m.changes != nil {
+		m.changes.record(key, true)
+	}
+	for _, f := range m.removeHandlers {
+		f(key, value)
+	}
+	m.notifyWatchers(Event{Type: EventRemove, Key: key, Old: value})
+	return
+}
+
 // Search searches the map with given `key`.
 // Second return parameter `found` is true if key was found, otherwise false.
 func (m *AnyAnyMap) Search(key interface{}) (value interface{}, found bool) {
+	if stats := m.getStats(); stats != nil {
+		stats.gets.Add(1)
+	}
 	m.mu.RLock()
 	if m.data != nil {
 		value, found = m.data[key]
@@ -156,6 +704,9 @@ func (m *AnyAnyMap) Search(key interface{}) (value interface{}, found bool) {
 
 // Get returns the value by given `key`.
 func (m *AnyAnyMap) Get(key interface{}) (value interface{}) {
+	if stats := m.getStats(); stats != nil {
+		stats.gets.Add(1)
+	}
 	m.mu.RLock()
 	if m.data != nil {
 		value = m.data[key]
@@ -164,12 +715,98 @@ func (m *AnyAnyMap) Get(key interface{}) (value interface{}) {
 	return
 }
 
+// Gets returns the values by given `keys` within one RWMutex.RLock.
+// The returned values are in the same order as `keys`, and the value is nil if its key does not exist.
+func (m *AnyAnyMap) Gets(keys []interface{}) []interface{} {
+	if stats := m.getStats(); stats != nil {
+		stats.gets.Add(int64(len(keys)))
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	values := make([]interface{}, len(keys))
+	for i, key := range keys {
+		values[i] = m.data[key]
+	}
+	return values
+}
+
+// GetMap returns the key-values of given `keys` that exist in the map as a new map
+// within one RWMutex.RLock.
+func (m *AnyAnyMap) GetMap(keys []interface{}) map[interface{}]interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	data := make(map[interface{}]interface{})
+	for _, key := range keys {
+		if v, ok := m.data[key]; ok {
+			data[key] = v
+		}
+	}
+	return data
+}
+
+// GetOrDefault returns the value by given `key`, or returns `def` if the `key` does not exist.
+// It returns the stored value even if it is nil, and it never changes the map unlike GetOrSet.
+func (m *AnyAnyMap) GetOrDefault(key interface{}, def interface{}) interface{} {
+	if v, ok := m.Search(key); ok {
+		return v
+	}
+	return def
+}
+
+// GetByPath returns the value by dotted path `path` like "server.listeners.0.port",
+// in which each segment is a key of the map or nested map, or an index of nested slice.
+// The nested value can be of type map[string]interface{}, map[interface{}]interface{} or []interface{}.
+// It returns nil if any segment of `path` is missing.
+func (m *AnyAnyMap) GetByPath(path string) interface{} {
+	if path == "" {
+		return nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	value, _ := getByPath(m.data, strings.Split(path, "."))
+	return value
+}
+
+// SetByPath sets `value` by dotted path `path` like "server.listeners.0.port",
+// in which each segment is a key of the map or nested map, or an index of nested slice.
+// The missing intermediate segments are created as map[string]interface{}.
+//
+// The nested maps and slices along the path are copied but not changed in place, and the copied value
+// is set to the key of the first segment, so that the nested setting is the same as the Set of the key,
+// and never changes the nested values shared with others, like the clones of the map.
+//
+// It returns an error if `path` is empty, or any intermediate segment exists but is not a map or slice,
+// or the index of a slice segment is out of range.
+func (m *AnyAnyMap) SetByPath(path string, value interface{}) error {
+	m.checkFrozen()
+	if path == "" {
+		return gerror.NewCode(gcode.CodeInvalidParameter, `empty path`)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.data == nil {
+		m.data = make(map[interface{}]interface{})
+	}
+	segments := strings.Split(path, ".")
+	if len(segments) == 1 {
+		m.doSet(path, value)
+		return nil
+	}
+	root, err := setByPath(m.data[segments[0]], segments, 1, value)
+	if err != nil {
+		return err
+	}
+	m.doSet(segments[0], root)
+	return nil
+}
+
 // Pop retrieves and deletes an item from the map.
 func (m *AnyAnyMap) Pop() (key, value interface{}) {
+	m.checkFrozen()
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	for key, value = range m.data {
-		delete(m.data, key)
+		m.doRemove(key)
 		return
 	}
 	return
@@ -178,6 +815,7 @@ func (m *AnyAnyMap) Pop() (key, value interface{}) {
 // Pops retrieves and deletes `size` items from the map.
 // It returns all items if size == -1.
 func (m *AnyAnyMap) Pops(size int) map[interface{}]interface{} {
+	m.checkFrozen()
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	if size > len(m.data) || size == -1 {
@@ -191,7 +829,7 @@ func (m *AnyAnyMap) Pops(size int) map[interface{}]interface{} {
 		newMap = make(map[interface{}]interface{}, size)
 	)
 	for k, v := range m.data {
-		delete(m.data, k)
+		m.doRemove(k)
 		newMap[k] = v
 		index++
 		if index == size {
@@ -209,31 +847,36 @@ func (m *AnyAnyMap) Pops(size int) map[interface{}]interface{} {
 // it will be executed with mutex.Lock of the hash map,
 // and its return value will be set to the map with `key`.
 //
-// It returns value with given `key`.
-func (m *AnyAnyMap) doSetWithLockCheck(key interface{}, value interface{}) interface{} {
+// It returns value with given `key`, and `computed` is true if the value is produced by this call
+// but not an existing one in the map.
+func (m *AnyAnyMap) doSetWithLockCheck(key interface{}, value interface{}) (result interface{}, computed bool) {
+	m.checkFrozen()
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	if m.data == nil {
 		m.data = make(map[interface{}]interface{})
 	}
 	if v, ok := m.data[key]; ok {
-		return v
+		return v, false
 	}
 	if f, ok := value.(func() interface{}); ok {
 		value = f()
 	}
 	if value != nil {
-		m.data[key] = value
+		m.doSet(key, value)
 	}
-	return value
+	return value, true
 }
 
 // GetOrSet returns the value by key,
 // or sets value with given `value` if it does not exist and then returns this value.
 func (m *AnyAnyMap) GetOrSet(key interface{}, value interface{}) interface{} {
 	if v, ok := m.Search(key); !ok {
-		return m.doSetWithLockCheck(key, value)
+		v, computed := m.doSetWithLockCheck(key, value)
+		m.recordLookup(!computed)
+		return v
 	} else {
+		m.recordLookup(true)
 		return v
 	}
 }
@@ -241,12 +884,19 @@ func (m *AnyAnyMap) GetOrSet(key interface{}, value interface{}) interface{} {
 // GetOrSetFunc returns the value by key,
 // or sets value with returned value of callback function `f` if it does not exist
 // and then returns this value.
+//
+// The function `f` is executed without the lock of the hash map, and it is executed only once for the same key
+// by concurrent callers, in which the other callers wait for and share its result. Note that the result of `f`
+// is not set to the map if it is nil, and if `f` panics, the waiting callers get nil.
 func (m *AnyAnyMap) GetOrSetFunc(key interface{}, f func() interface{}) interface{} {
-	if v, ok := m.Search(key); !ok {
-		return m.doSetWithLockCheck(key, f())
-	} else {
+	if v, ok := m.Search(key); ok {
+		m.recordLookup(true)
 		return v
 	}
+	v, _ := m.doLoad(key, func(key interface{}) (interface{}, error) {
+		return f(), nil
+	}, false)
+	return v
 }
 
 // GetOrSetFuncLock returns the value by key,
@@ -257,19 +907,157 @@ func (m *AnyAnyMap) GetOrSetFunc(key interface{}, f func() interface{}) interfac
 // with mutex.Lock of the hash map.
 func (m *AnyAnyMap) GetOrSetFuncLock(key interface{}, f func() interface{}) interface{} {
 	if v, ok := m.Search(key); !ok {
-		return m.doSetWithLockCheck(key, f)
+		v, computed := m.doSetWithLockCheck(key, f)
+		m.recordLookup(!computed)
+		return v
 	} else {
+		m.recordLookup(true)
 		return v
 	}
 }
 
-// GetVar returns a Var with the value by given `key`.
-// The returned Var is un-concurrent safe.
-func (m *AnyAnyMap) GetVar(key interface{}) *gvar.Var {
-	return gvar.New(m.Get(key))
-}
-
-// GetVarOrSet returns a Var with result from GetOrSet.
+// GetOrSetFuncLockX returns the value by key,
+// or sets value with returned value of callback function `f` if it does not exist
+// and then returns this value, just like GetOrSetFuncLock.
+//
+// The returned `computed` is true if function `f` is executed by this call and its result is returned,
+// or false if the value already exists, which might be set by another goroutine concurrently.
+// Note that the result of `f` is not set to the map if it is nil.
+func (m *AnyAnyMap) GetOrSetFuncLockX(key interface{}, f func() interface{}) (value interface{}, computed bool) {
+	if v, ok := m.Search(key); ok {
+		m.recordLookup(true)
+		return v, false
+	}
+	value, computed = m.doSetWithLockCheck(key, f)
+	m.recordLookup(!computed)
+	return
+}
+
+// Increment adds `delta` to the numeric value of `key` atomically within one RWMutex.Lock,
+// and returns the new value. The missing or non-numeric value is considered as 0.
+//
+// The new value is stored as the same numeric type as the current value if possible,
+// for example, an int value keeps int if the new value is a whole number, or else it is stored as float64.
+func (m *AnyAnyMap) Increment(key interface{}, delta float64) float64 {
+	m.checkFrozen()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.data == nil {
+		m.data = make(map[interface{}]interface{})
+	}
+	current := m.data[key]
+	number, ok := toFloat64(current)
+	if !ok {
+		number = 0
+	}
+	number += delta
+	m.doSet(key, fromFloat64(number, current))
+	return number
+}
+
+// Decrement subtracts `delta` from the numeric value of `key` atomically within one RWMutex.Lock,
+// and returns the new value. It is the same as Increment with negative `delta`.
+func (m *AnyAnyMap) Decrement(key interface{}, delta float64) float64 {
+	return m.Increment(key, -delta)
+}
+
+// LoadOrStore returns the existing value for the `key` if present, or else it stores and returns
+// the given `value`. The `loaded` result is true if the value was loaded, false if stored.
+//
+// It is compatible with sync.Map.LoadOrStore, and it differs with GetOrSet that it acquires only
+// RWMutex.Lock once, and the nil `value` is also stored.
+func (m *AnyAnyMap) LoadOrStore(key interface{}, value interface{}) (actual interface{}, loaded bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if v, ok := m.data[key]; ok {
+		m.recordLookup(true)
+		return v, true
+	}
+	m.recordLookup(false)
+	m.checkFrozen()
+	if m.data == nil {
+		m.data = make(map[interface{}]interface{})
+	}
+	m.doSet(key, value)
+	return value, false
+}
+
+// GetOrLoad returns the value by key, or loads the value using function `loader`
+// if it does not exist and then sets it to the map and returns it.
+//
+// The `loader` is called without the lock of the map, and it is called only once for the same key
+// by concurrent callers, in which the other callers wait for and share its result.
+// If `loader` returns an error, nothing is set to the map and the error is returned.
+func (m *AnyAnyMap) GetOrLoad(
+	key interface{}, loader func(key interface{}) (interface{}, error),
+) (value interface{}, err error) {
+	return m.doLoad(key, loader, true)
+}
+
+// doLoad returns the value by key, or loads the value using function `loader` for GetOrLoad and GetOrSetFunc,
+// in which the `loader` is called only once for the same key by concurrent callers.
+// The nil value returned by `loader` is set to the map only if `setNil` is true.
+// If the key is set by others while loading, the existing value is kept and returned.
+func (m *AnyAnyMap) doLoad(
+	key interface{}, loader func(key interface{}) (interface{}, error), setNil bool,
+) (value interface{}, err error) {
+	m.mu.Lock()
+	if v, ok := m.data[key]; ok {
+		m.mu.Unlock()
+		m.recordLookup(true)
+		return v, nil
+	}
+	if call, ok := m.loading[key]; ok {
+		m.mu.Unlock()
+		m.recordLookup(true)
+		call.wg.Wait()
+		return call.value, call.err
+	}
+	if m.frozen.Val() {
+		m.mu.Unlock()
+		m.checkFrozen()
+	}
+	m.recordLookup(false)
+	if m.loading == nil {
+		m.loading = make(map[interface{}]*gAnyAnyMapLoadCall)
+	}
+	call := &gAnyAnyMapLoadCall{}
+	call.wg.Add(1)
+	m.loading[key] = call
+	m.mu.Unlock()
+
+	var returned bool
+	defer func() {
+		if !returned {
+			call.err = gerror.NewCodef(gcode.CodeInternalPanic, `loader panics for key "%v"`, key)
+		}
+		m.mu.Lock()
+		delete(m.loading, key)
+		if v, ok := m.data[key]; ok && returned {
+			// The key is set while loading, and the existing value is kept, just like doSetWithLockCheck.
+			call.value, call.err = v, nil
+			value, err = v, nil
+		} else if call.err == nil && (setNil || call.value != nil) {
+			if m.data == nil {
+				m.data = make(map[interface{}]interface{})
+			}
+			m.doSet(key, call.value)
+		}
+		m.mu.Unlock()
+		call.wg.Done()
+	}()
+	call.value, call.err = loader(key)
+	returned = true
+	return call.value, call.err
+}
+
+// GetVar returns a Var with the value by given `key`.
+// The returned Var is un-concurrent safe.
+func (m *AnyAnyMap) GetVar(key interface{}) *gvar.Var {
+	return gvar.New(m.Get(key))
+}
+
+// GetVarOrSet returns a Var with result from GetOrSet.
 // The returned Var is un-concurrent safe.
 func (m *AnyAnyMap) GetVarOrSet(key interface{}, value interface{}) *gvar.Var {
 	return gvar.New(m.GetOrSet(key, value))
@@ -287,6 +1075,48 @@ func (m *AnyAnyMap) GetVarOrSetFuncLock(key interface{}, f func() interface{}) *
 	return gvar.New(m.GetOrSetFuncLock(key, f))
 }
 
+// GetInt returns the value by given `key` converted to int.
+// The returned `ok` is false if the `key` does not exist, or its value cannot be converted to int,
+// in which the numeric value, numeric string and bool are converted, see Scan.
+func (m *AnyAnyMap) GetInt(key interface{}) (value int, ok bool) {
+	ok = m.getAs(key, &value)
+	return
+}
+
+// GetString returns the value by given `key` converted to string.
+// The returned `ok` is false if the `key` does not exist, or its value cannot be converted to string,
+// in which the numeric value, []byte and bool are converted, see Scan.
+func (m *AnyAnyMap) GetString(key interface{}) (value string, ok bool) {
+	ok = m.getAs(key, &value)
+	return
+}
+
+// GetBool returns the value by given `key` converted to bool.
+// The returned `ok` is false if the `key` does not exist, or its value cannot be converted to bool,
+// in which the string is converted by strconv.ParseBool, see Scan.
+func (m *AnyAnyMap) GetBool(key interface{}) (value bool, ok bool) {
+	ok = m.getAs(key, &value)
+	return
+}
+
+// GetFloat64 returns the value by given `key` converted to float64.
+// The returned `ok` is false if the `key` does not exist, or its value cannot be converted to float64,
+// in which the numeric value, numeric string and bool are converted, see Scan.
+func (m *AnyAnyMap) GetFloat64(key interface{}) (value float64, ok bool) {
+	ok = m.getAs(key, &value)
+	return
+}
+
+// getAs searches the value by given `key` and converts it to the type of `pointer` using assignValue.
+// It returns false if the `key` does not exist, or its value is nil or cannot be converted.
+func (m *AnyAnyMap) getAs(key interface{}, pointer interface{}) bool {
+	value, found := m.Search(key)
+	if !found || value == nil {
+		return false
+	}
+	return assignValue(reflect.ValueOf(pointer).Elem(), value) == nil
+}
+
 // SetIfNotExist sets `value` to the map if the `key` does not exist, and then returns true.
 // It returns false if `key` exists, and `value` would be ignored.
 func (m *AnyAnyMap) SetIfNotExist(key interface{}, value interface{}) bool {
@@ -297,6 +1127,17 @@ func (m *AnyAnyMap) SetIfNotExist(key interface{}, value interface{}) bool {
 	return false
 }
 
+// SetIfNotExistGet sets `value` to the map if the `key` does not exist, and then returns `value` and true.
+// It returns the existing value and false if `key` exists, and `value` would be ignored.
+// The checking and setting are within one RWMutex.Lock, so the returned value is always the current one.
+//
+// Just like SetIfNotExist, if `value` is type of `func() interface {}`, it is executed within the lock
+// and its result is set. The nil value is never set to the map, for which it returns nil and false.
+func (m *AnyAnyMap) SetIfNotExistGet(key interface{}, value interface{}) (stored interface{}, created bool) {
+	stored, created = m.doSetWithLockCheck(key, value)
+	return stored, created && stored != nil
+}
+
 // SetIfNotExistFunc sets value with return value of callback function `f`, and then returns true.
 // It returns false if `key` exists, and `value` would be ignored.
 func (m *AnyAnyMap) SetIfNotExistFunc(key interface{}, f func() interface{}) bool {
@@ -320,30 +1161,139 @@ func (m *AnyAnyMap) SetIfNotExistFuncLock(key interface{}, f func() interface{})
 	return false
 }
 
+// CompareAndSwap sets `newValue` to the map with `key` if the `key` exists and its value is equal to
+// `oldValue` using reflect.DeepEqual, and then returns true. It returns false if the swap is not performed.
+// The comparison and swap are within one RWMutex.Lock.
+func (m *AnyAnyMap) CompareAndSwap(key interface{}, oldValue, newValue interface{}) bool {
+	m.checkFrozen()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if v, ok := m.data[key]; !ok || !valuesEqual(v, oldValue) {
+		return false
+	}
+	m.doSet(key, newValue)
+	return true
+}
+
+// CompareAndDelete deletes the `key` from the map if it exists and its value is equal to
+// `oldValue` using reflect.DeepEqual, and then returns true. It returns false if the deletion
+// is not performed. The comparison and deletion are within one RWMutex.Lock.
+func (m *AnyAnyMap) CompareAndDelete(key interface{}, oldValue interface{}) bool {
+	m.checkFrozen()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if v, ok := m.data[key]; !ok || !valuesEqual(v, oldValue) {
+		return false
+	}
+	m.doRemove(key)
+	return true
+}
+
+// Move moves the value of key `from` to key `to` within one RWMutex.Lock, which overwrites the value
+// of `to` if it exists, and then returns true. It returns false and does nothing if `from` does not exist.
+func (m *AnyAnyMap) Move(from, to interface{}) bool {
+	m.checkFrozen()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, ok := m.data[from]
+	if !ok {
+		return false
+	}
+	if from != to {
+		m.doRemove(from)
+		m.doSet(to, value)
+	}
+	return true
+}
+
 // Remove deletes value from map by given `key`, and return this deleted value.
 func (m *AnyAnyMap) Remove(key interface{}) (value interface{}) {
+	m.checkFrozen()
 	m.mu.Lock()
-	if m.data != nil {
-		var ok bool
-		if value, ok = m.data[key]; ok {
-			delete(m.data, key)
-		}
-	}
+	value, _ = m.doRemove(key)
 	m.mu.Unlock()
 	return
 }
 
 // Removes batch deletes values of the map by keys.
 func (m *AnyAnyMap) Removes(keys []interface{}) {
+	m.checkFrozen()
 	m.mu.Lock()
-	if m.data != nil {
-		for _, key := range keys {
-			delete(m.data, key)
-		}
+	for _, key := range keys {
+		m.doRemove(key)
 	}
 	m.mu.Unlock()
 }
 
+// GetWithPrefix returns the key-values of which the key starts with `prefix`
+// as a new map within one RWMutex.RLock. The keys are converted to string for matching.
+func (m *AnyAnyMap) GetWithPrefix(prefix string) map[interface{}]interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	data := make(map[interface{}]interface{})
+	for k, v := range m.data {
+		if strings.HasPrefix(gconv.String(k), prefix) {
+			data[k] = v
+		}
+	}
+	return data
+}
+
+// RemoveWithPrefix deletes the key-values of which the key starts with `prefix`,
+// and returns the count of deleted items. The keys are converted to string for matching.
+func (m *AnyAnyMap) RemoveWithPrefix(prefix string) int {
+	m.checkFrozen()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	count := 0
+	for k := range m.data {
+		if strings.HasPrefix(gconv.String(k), prefix) {
+			m.doRemove(k)
+			count++
+		}
+	}
+	return count
+}
+
+// KeysMatch returns the keys matching shell pattern `pattern` within one RWMutex.RLock,
+// in which the keys are converted to string for matching. The `pattern` syntax is the same as path.Match,
+// for example, "session:*" matches all the keys starting with "session:", and "user:*:name" matches the
+// keys like "user:1:name". It returns nil if `pattern` is malformed.
+func (m *AnyAnyMap) KeysMatch(pattern string) []interface{} {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var keys []interface{}
+	for k := range m.data {
+		if matched, _ := path.Match(pattern, gconv.String(k)); matched {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// RemoveMatch deletes the key-values of which the key matches shell pattern `pattern` within one RWMutex.Lock,
+// and returns the count of deleted items. The keys are matched as KeysMatch.
+// It deletes nothing if `pattern` is malformed.
+func (m *AnyAnyMap) RemoveMatch(pattern string) int {
+	m.checkFrozen()
+	if _, err := path.Match(pattern, ""); err != nil {
+		return 0
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	count := 0
+	for k := range m.data {
+		if matched, _ := path.Match(pattern, gconv.String(k)); matched {
+			m.doRemove(k)
+			count++
+		}
+	}
+	return count
+}
+
 // Keys returns all keys of the map as a slice.
 func (m *AnyAnyMap) Keys() []interface{} {
 	m.mu.RLock()
@@ -359,6 +1309,20 @@ func (m *AnyAnyMap) Keys() []interface{} {
 	return keys
 }
 
+// SortedKeys returns all keys of the map in ascending order within one RWMutex.RLock.
+// If all the keys are strings, signed integers, unsigned integers or floats, they are sorted by their values,
+// or else they are sorted by their string representation of fmt.Sprint.
+func (m *AnyAnyMap) SortedKeys() []interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	keys := make([]interface{}, 0, len(m.data))
+	for key := range m.data {
+		keys = append(keys, key)
+	}
+	sortKeysByKind(keys)
+	return keys
+}
+
 // Values returns all values of the map as a slice.
 func (m *AnyAnyMap) Values() []interface{} {
 	m.mu.RLock()
@@ -374,6 +1338,109 @@ func (m *AnyAnyMap) Values() []interface{} {
 	return values
 }
 
+// ValuesNonNil returns all values of the map that are not nil as a slice.
+func (m *AnyAnyMap) ValuesNonNil() []interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	values := make([]interface{}, 0, len(m.data))
+	for _, value := range m.data {
+		if value != nil {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// Entries returns all key-value pairs of the map as a slice.
+func (m *AnyAnyMap) Entries() []Entry {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	entries := make([]Entry, 0, len(m.data))
+	for k, v := range m.data {
+		entries = append(entries, Entry{Key: k, Value: v})
+	}
+	return entries
+}
+
+// Chunk splits the keys of the map into chunks of at most `size` keys within one RWMutex.RLock.
+// The keys are sorted by their string representation of fmt.Sprint, so that the chunks are repeatable
+// if the map is not changed. It returns nil if `size` < 1.
+func (m *AnyAnyMap) Chunk(size int) [][]interface{} {
+	if size < 1 {
+		return nil
+	}
+	keys := m.sortedKeys(nil)
+	chunks := make([][]interface{}, 0, (len(keys)+size-1)/size)
+	for i := 0; i < len(keys); i += size {
+		end := i + size
+		if end > len(keys) {
+			end = len(keys)
+		}
+		chunks = append(chunks, keys[i:end:end])
+	}
+	return chunks
+}
+
+// Page returns at most `limit` entries of the map after skipping `offset` entries within one RWMutex.RLock,
+// which is used for paginating the map. The entries are sorted by key using the optional `comparator`,
+// or by the string representation of key by fmt.Sprint in default, so that the pages are repeatable
+// if the map is not changed.
+func (m *AnyAnyMap) Page(offset, limit int, comparator ...func(a, b interface{}) int) []Entry {
+	if offset < 0 {
+		offset = 0
+	}
+	if limit < 1 {
+		return nil
+	}
+	var cmp func(a, b interface{}) int
+	if len(comparator) > 0 {
+		cmp = comparator[0]
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	keys := m.doSortedKeys(cmp)
+	if offset >= len(keys) {
+		return nil
+	}
+	if offset+limit < len(keys) {
+		keys = keys[offset : offset+limit]
+	} else {
+		keys = keys[offset:]
+	}
+	entries := make([]Entry, len(keys))
+	for i, key := range keys {
+		entries[i] = Entry{Key: key, Value: m.data[key]}
+	}
+	return entries
+}
+
+// sortedKeys returns the keys of the map sorted by `comparator` within RWMutex.RLock, see sortKeys.
+func (m *AnyAnyMap) sortedKeys(comparator func(a, b interface{}) int) []interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.doSortedKeys(comparator)
+}
+
+// doSeededKeys returns the keys of the map in deterministic order of `seed` without mutex, see SetIterationSeed.
+func (m *AnyAnyMap) doSeededKeys(seed int64) []interface{} {
+	keys := make([]interface{}, 0, len(m.data))
+	for key := range m.data {
+		keys = append(keys, key)
+	}
+	sortKeysBySeed(keys, seed)
+	return keys
+}
+
+// doSortedKeys returns the keys of the map sorted by `comparator` without mutex, see sortKeys.
+func (m *AnyAnyMap) doSortedKeys(comparator func(a, b interface{}) int) []interface{} {
+	keys := make([]interface{}, 0, len(m.data))
+	for key := range m.data {
+		keys = append(keys, key)
+	}
+	sortKeys(keys, comparator)
+	return keys
+}
+
 // Contains checks whether a key exists.
 // It returns true if the `key` exists, or else false.
 func (m *AnyAnyMap) Contains(key interface{}) bool {
@@ -394,33 +1461,136 @@ func (m *AnyAnyMap) Size() int {
 	return length
 }
 
+// SizeInBytes returns the estimated memory size in bytes of the keys and values of the map within
+// one RWMutex.RLock, which is used for capacity planning.
+//
+// Note that it is a rough estimate: it sums up the sizes of the types, and the backing data of strings,
+// slices, maps and pointers that the keys and values refer to. The data shared by several entries is
+// counted several times, and the memory overhead of the underlying map buckets is not counted.
+func (m *AnyAnyMap) SizeInBytes() int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var (
+		size    int64
+		visited = make(map[uintptr]struct{})
+	)
+	for k, v := range m.data {
+		size += estimateSize(reflect.ValueOf(&k).Elem(), visited)
+		size += estimateSize(reflect.ValueOf(&v).Elem(), visited)
+	}
+	return size
+}
+
 // IsEmpty checks whether the map is empty.
 // It returns true if map is empty, or else false.
 func (m *AnyAnyMap) IsEmpty() bool {
 	return m.Size() == 0
 }
 
+// Count returns the count of the items of which the key-value satisfies the callback function `f`.
+// It calls `f` within RWMutex.RLock, so `f` should not change the map.
+func (m *AnyAnyMap) Count(f func(k interface{}, v interface{}) bool) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	count := 0
+	for k, v := range m.data {
+		if f(k, v) {
+			count++
+		}
+	}
+	return count
+}
+
+// SumValues returns the sum of the numeric values of the map as float64.
+// The numeric strings like "42" are also counted, and the non-numeric values are skipped.
+func (m *AnyAnyMap) SumValues() float64 {
+	sum, _ := m.sumValues()
+	return sum
+}
+
+// AvgValues returns the average of the numeric values of the map as float64,
+// or 0 if there is no numeric value.
+// The numeric strings like "42" are also counted, and the non-numeric values are skipped.
+func (m *AnyAnyMap) AvgValues() float64 {
+	sum, count := m.sumValues()
+	if count == 0 {
+		return 0
+	}
+	return sum / float64(count)
+}
+
+// sumValues returns the sum and count of the numeric values of the map within RWMutex.RLock.
+func (m *AnyAnyMap) sumValues() (sum float64, count int) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, v := range m.data {
+		if f, ok := toFloat64(v); ok {
+			sum += f
+			count++
+		}
+	}
+	return
+}
+
 // Clear deletes all data of the map, it will remake a new underlying data map.
 func (m *AnyAnyMap) Clear() {
+	m.checkFrozen()
 	m.mu.Lock()
-	m.data = make(map[interface{}]interface{})
+	m.doReplace(make(map[interface{}]interface{}))
+	m.notifyWatchers(Event{Type: EventClear})
 	m.mu.Unlock()
 }
 
 // Replace the data of the map with given `data`.
 func (m *AnyAnyMap) Replace(data map[interface{}]interface{}) {
+	m.checkFrozen()
 	m.mu.Lock()
-	m.data = data
+	m.doReplace(data)
 	m.mu.Unlock()
 }
 
+// Transform replaces each value of the map with the result of callback function `f` within RWMutex.Lock,
+// and the keys of the map are kept unchanged.
+// Note that `f` should not call any method of the map as it would deadlock.
+func (m *AnyAnyMap) Transform(f func(k interface{}, v interface{}) interface{}) {
+	m.checkFrozen()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for k, v := range m.data {
+		m.doSet(k, f(k, v))
+	}
+}
+
 // LockFunc locks writing with given callback function `f` within RWMutex.Lock.
+//
+// The parameter `m` of `f` is the underlying data map, which can be read and written directly in `f`.
+// Note that `f` should not call any method of the map, or the goroutines it waits for should not either,
+// as the RWMutex is not reentrant and it would deadlock.
 func (m *AnyAnyMap) LockFunc(f func(m map[interface{}]interface{})) {
+	m.checkFrozen()
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	if m.data == nil {
+		m.data = make(map[interface{}]interface{})
+	}
+	m.detach()
 	f(m.data)
 }
 
+// DoUnderLock is alias of LockFunc.
+// It calls `f` with the underlying data map within RWMutex.Lock, in which the data map should be
+// read and written directly instead of calling methods of the map.
+func (m *AnyAnyMap) DoUnderLock(f func(data map[interface{}]interface{})) {
+	m.LockFunc(f)
+}
+
+// WithData is alias of LockFunc.
+// It calls `f` with the underlying data map within RWMutex.Lock, and no method of the map
+// locks the map again during calling `f`.
+func (m *AnyAnyMap) WithData(f func(data map[interface{}]interface{})) {
+	m.LockFunc(f)
+}
+
 // RLockFunc locks reading with given callback function `f` within RWMutex.RLock.
 func (m *AnyAnyMap) RLockFunc(f func(m map[interface{}]interface{})) {
 	m.mu.RLock()
@@ -428,32 +1598,230 @@ func (m *AnyAnyMap) RLockFunc(f func(m map[interface{}]interface{})) {
 	f(m.data)
 }
 
+// RLockFuncR locks reading with given callback function `f` within RWMutex.RLock,
+// and returns the result of `f`.
+func (m *AnyAnyMap) RLockFuncR(f func(m map[interface{}]interface{}) interface{}) interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return f(m.data)
+}
+
+// LockFuncR locks writing with given callback function `f` within RWMutex.Lock, just like LockFunc,
+// and returns the result of `f`.
+func (m *AnyAnyMap) LockFuncR(f func(m map[interface{}]interface{}) interface{}) interface{} {
+	m.checkFrozen()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.data == nil {
+		m.data = make(map[interface{}]interface{})
+	}
+	m.detach()
+	return f(m.data)
+}
+
+// LockFuncErr locks writing with given callback function `f` within RWMutex.Lock,
+// and returns the error returned by `f`.
+//
+// If `f` panics, the panic is recovered and returned as an error, and the lock is released.
+// Note that the changes made by `f` to the map before it fails are not rolled back.
+func (m *AnyAnyMap) LockFuncErr(f func(m map[interface{}]interface{}) error) (err error) {
+	m.checkFrozen()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	defer func() {
+		if exception := recover(); exception != nil {
+			if v, ok := exception.(error); ok && gerror.HasStack(v) {
+				err = v
+			} else {
+				err = gerror.NewCodef(gcode.CodeInternalPanic, "%+v", exception)
+			}
+		}
+	}()
+	if m.data == nil {
+		m.data = make(map[interface{}]interface{})
+	}
+	m.detach()
+	return f(m.data)
+}
+
+// LockFuncCtx locks writing with given callback function `f` within RWMutex.Lock, just like LockFunc,
+// but it gives up waiting for the lock and returns ctx.Err() if `ctx` is done before the lock is acquired.
+// It returns nil after `f` is called.
+//
+// It tries locking repeatedly with an increasing backoff interval until the lock is acquired.
+func (m *AnyAnyMap) LockFuncCtx(ctx context.Context, f func(m map[interface{}]interface{})) error {
+	m.checkFrozen()
+	const (
+		minBackoff = 100 * time.Microsecond
+		maxBackoff = 10 * time.Millisecond
+	)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for backoff := minBackoff; !m.mu.TryLock(); {
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		if backoff < maxBackoff {
+			backoff *= 2
+		}
+	}
+	defer m.mu.Unlock()
+	if m.data == nil {
+		m.data = make(map[interface{}]interface{})
+	}
+	m.detach()
+	f(m.data)
+	return nil
+}
+
+// TryLockFunc tries locking writing and calls given callback function `f` within RWMutex.Lock
+// if the lock is acquired. It returns false immediately without calling `f` if the lock cannot be acquired.
+func (m *AnyAnyMap) TryLockFunc(f func(m map[interface{}]interface{})) bool {
+	m.checkFrozen()
+	if !m.mu.TryLock() {
+		return false
+	}
+	defer m.mu.Unlock()
+	m.detach()
+	f(m.data)
+	return true
+}
+
+// TryRLockFunc tries locking reading and calls given callback function `f` within RWMutex.RLock
+// if the lock is acquired. It returns false immediately without calling `f` if the lock cannot be acquired.
+func (m *AnyAnyMap) TryRLockFunc(f func(m map[interface{}]interface{})) bool {
+	if !m.mu.TryRLock() {
+		return false
+	}
+	defer m.mu.RUnlock()
+	f(m.data)
+	return true
+}
+
 // Flip exchanges key-value of the map to value-key.
 func (m *AnyAnyMap) Flip() {
+	m.checkFrozen()
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	n := make(map[interface{}]interface{}, len(m.data))
 	for k, v := range m.data {
 		n[v] = k
 	}
-	m.data = n
+	m.doReplace(n)
+}
+
+// RekeyFunc rebuilds the map by transforming each key with callback function `f` within one RWMutex.Lock,
+// which is useful for migrating data like renaming keys. The `f` returns the new key of each item,
+// and the item is dropped if `keep` is false. The new data map is swapped in after all items are
+// transformed, and like Flip, it does not call the OnSet and OnRemove handlers.
+//
+// If multiple keys are transformed to the same new key, the one transformed last wins. Note that the
+// iteration order of the map is random, so the value of the colliding new key is undefined, and `f` should
+// avoid collisions if it matters. Note also that `f` should not call any method of the map as it would deadlock.
+func (m *AnyAnyMap) RekeyFunc(f func(oldKey, value interface{}) (newKey interface{}, keep bool)) {
+	m.checkFrozen()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data := make(map[interface{}]interface{}, len(m.data))
+	for k, v := range m.data {
+		if newKey, keep := f(k, v); keep {
+			data[newKey] = v
+		}
+	}
+	m.doReplace(data)
+}
+
+// OverlayEnv sets the environment variables whose names start with `prefix` to the map, overwriting
+// the existing values. The key is the name with `prefix` and the leading underscores trimmed, which is
+// lowercased and has the underscores replaced with dots, like `APP_DB_HOST` to `db.host` for prefix `APP_`.
+// The values are kept as strings, and the variables having empty keys are ignored.
+func (m *AnyAnyMap) OverlayEnv(prefix string) {
+	data := make(map[interface{}]interface{})
+	for _, env := range os.Environ() {
+		name, value, _ := strings.Cut(env, "=")
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		key := strings.TrimLeft(name[len(prefix):], "_")
+		if key == "" {
+			continue
+		}
+		data[strings.ReplaceAll(strings.ToLower(key), "_", ".")] = value
+	}
+	if len(data) > 0 {
+		m.Sets(data)
+	}
 }
 
 // Merge merges two hash maps.
 // The `other` map will be merged into the map `m`.
 func (m *AnyAnyMap) Merge(other *AnyAnyMap) {
+	m.checkFrozen()
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	if m.data == nil {
-		m.data = other.MapCopy()
-		return
+		m.data = make(map[interface{}]interface{})
 	}
 	if other != m {
 		other.mu.RLock()
 		defer other.mu.RUnlock()
 	}
 	for k, v := range other.data {
-		m.data[k] = v
+		m.doSet(k, v)
+	}
+}
+
+// MergeReport merges `other` into the map `m` just like Merge, in which the values of `other` win,
+// and returns the keys that exist in both maps with different values using reflect.DeepEqual,
+// which are overridden by the merging. The returned keys are in random order.
+//
+// The two maps are locked in a fixed order, so that merging them into each other concurrently does not deadlock.
+func (m *AnyAnyMap) MergeReport(other *AnyAnyMap) (conflicts []interface{}) {
+	m.checkFrozen()
+	if m == other || other == nil {
+		return nil
+	}
+	if reflect.ValueOf(m).Pointer() < reflect.ValueOf(other).Pointer() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		other.mu.RLock()
+		defer other.mu.RUnlock()
+	} else {
+		other.mu.RLock()
+		defer other.mu.RUnlock()
+		m.mu.Lock()
+		defer m.mu.Unlock()
+	}
+	if m.data == nil {
+		m.data = make(map[interface{}]interface{})
+	}
+	for k, v := range other.data {
+		if oldValue, ok := m.data[k]; ok && !valuesEqual(oldValue, v) {
+			conflicts = append(conflicts, k)
+		}
+		m.doSet(k, v)
+	}
+	return conflicts
+}
+
+// MergeMaps merges all given `maps` into the map `m` within one RWMutex.Lock.
+// The latter map in `maps` has higher priority if there are duplicated keys.
+func (m *AnyAnyMap) MergeMaps(maps ...map[interface{}]interface{}) {
+	m.checkFrozen()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.data == nil {
+		m.data = make(map[interface{}]interface{})
+	}
+	for _, data := range maps {
+		for k, v := range data {
+			m.doSet(k, v)
+		}
 	}
 }
 
@@ -473,6 +1841,7 @@ func (m AnyAnyMap) MarshalJSON() ([]byte, error) {
 
 // UnmarshalJSON implements the interface UnmarshalJSON for json.Unmarshal.
 func (m *AnyAnyMap) UnmarshalJSON(b []byte) error {
+	m.checkFrozen()
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	if m.data == nil {
@@ -482,25 +1851,64 @@ func (m *AnyAnyMap) UnmarshalJSON(b []byte) error {
 	if err := json.UnmarshalUseNumber(b, &data); err != nil {
 		return err
 	}
+	m.detach()
 	for k, v := range data {
 		m.data[k] = v
+		if m.changes != nil {
+			m.changes.record(k, false)
+		}
 	}
 	return nil
 }
 
 // UnmarshalValue is an interface implement which sets any type of value for map.
 func (m *AnyAnyMap) UnmarshalValue(value interface{}) (err error) {
+	m.checkFrozen()
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	if m.data == nil {
 		m.data = make(map[interface{}]interface{})
 	}
+	m.detach()
 	for k, v := range gconv.Map(value) {
 		m.data[k] = v
+		if m.changes != nil {
+			m.changes.record(k, false)
+		}
 	}
 	return
 }
 
+// GobEncode implements the interface gob.GobEncoder for encoding/gob.
+// Note that the keys and values should be gob-encodable, and their concrete types other than
+// the basic types should be registered by gob.Register.
+func (m *AnyAnyMap) GobEncode() ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var buffer bytes.Buffer
+	if err := gob.NewEncoder(&buffer).Encode(m.data); err != nil {
+		return nil, gerror.Wrap(err, `gob encoding map failed`)
+	}
+	return buffer.Bytes(), nil
+}
+
+// GobDecode implements the interface gob.GobDecoder for encoding/gob.
+// It replaces the data of the map with the decoded key-value pairs.
+func (m *AnyAnyMap) GobDecode(b []byte) error {
+	m.checkFrozen()
+	var data map[interface{}]interface{}
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&data); err != nil {
+		return gerror.Wrap(err, `gob decoding map failed`)
+	}
+	if data == nil {
+		data = make(map[interface{}]interface{})
+	}
+	m.mu.Lock()
+	m.doReplace(data)
+	m.mu.Unlock()
+	return nil
+}
+
 // DeepCopy implements interface for deep copy of current type.
 func (m *AnyAnyMap) DeepCopy() interface{} {
 	if m == nil {
@@ -516,6 +1924,43 @@ func (m *AnyAnyMap) DeepCopy() interface{} {
 	return NewFrom(data, m.mu.IsSafe())
 }
 
+// Equal checks whether the current map and `other` have the same keys,
+// and the values of the same key are equal using reflect.DeepEqual.
+func (m *AnyAnyMap) Equal(other *AnyAnyMap) bool {
+	return m.EqualFunc(other, valuesEqual)
+}
+
+// EqualFunc checks whether the current map and `other` have the same keys,
+// and the values of the same key are equal using custom function `eq`.
+//
+// The two maps are locked reading in a consistent order, so that it does not deadlock
+// even if the two maps are compared with each other concurrently.
+func (m *AnyAnyMap) EqualFunc(other *AnyAnyMap, eq func(a, b interface{}) bool) bool {
+	if m == other {
+		return true
+	}
+	if other == nil {
+		return false
+	}
+	first, second := m, other
+	if reflect.ValueOf(first).Pointer() > reflect.ValueOf(second).Pointer() {
+		first, second = second, first
+	}
+	first.mu.RLock()
+	defer first.mu.RUnlock()
+	second.mu.RLock()
+	defer second.mu.RUnlock()
+	if len(m.data) != len(other.data) {
+		return false
+	}
+	for k, v := range m.data {
+		if otherValue, ok := other.data[k]; !ok || !eq(v, otherValue) {
+			return false
+		}
+	}
+	return true
+}
+
 // IsSubOf checks whether the current map is a sub-map of `other`.
 func (m *AnyAnyMap) IsSubOf(other *AnyAnyMap) bool {
 	if m == other {
@@ -550,7 +1995,7 @@ func (m *AnyAnyMap) Diff(other *AnyAnyMap) (addedKeys, removedKeys, updatedKeys
 	for key := range m.data {
 		if _, ok := other.data[key]; !ok {
 			removedKeys = append(removedKeys, key)
-		} else if !reflect.DeepEqual(m.data[key], other.data[key]) {
+		} else if !valuesEqual(m.data[key], other.data[key]) {
 			updatedKeys = append(updatedKeys, key)
 		}
 	}
@@ -561,3 +2006,86 @@ func (m *AnyAnyMap) Diff(other *AnyAnyMap) (addedKeys, removedKeys, updatedKeys
 	}
 	return
 }
+
+// Sample returns at most `n` entries randomly chosen from the map with equal probability within one
+// RWMutex.RLock, using reservoir sampling. It returns all entries in random order if the map has no more
+// than `n` entries.
+func (m *AnyAnyMap) Sample(n int) []Entry {
+	if n < 1 {
+		return nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if n > len(m.data) {
+		n = len(m.data)
+	}
+	var (
+		i       = 0
+		entries = make([]Entry, 0, n)
+	)
+	for k, v := range m.data {
+		if i < n {
+			entries = append(entries, Entry{Key: k, Value: v})
+		} else if j := grand.Intn(i + 1); j < n {
+			entries[j] = Entry{Key: k, Value: v}
+		}
+		i++
+	}
+	return entries
+}
+
+// WeightedSample returns at most `n` distinct entries randomly chosen from the map within one RWMutex.RLock,
+// in which the probability of each entry being chosen is proportional to its weight returned by `weight`.
+// The entries having no positive weight are never chosen, so it returns fewer than `n` entries if there are
+// not enough entries of positive weight.
+func (m *AnyAnyMap) WeightedSample(n int, weight func(k, v interface{}) float64) []Entry {
+	if n < 1 {
+		return nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if n > len(m.data) {
+		n = len(m.data)
+	}
+	// It uses the algorithm A-Res of Efraimidis and Spirakis, which keeps the `n` entries having
+	// the largest scores log(u)/w, in which u is uniformly random in (0, 1) and w is the weight.
+	reservoir := make(weightedEntryHeap, 0, n)
+	for k, v := range m.data {
+		w := weight(k, v)
+		if !(w > 0) || math.IsInf(w, 1) {
+			continue
+		}
+		score := math.Log(1-rand.Float64()) / w
+		if len(reservoir) < n {
+			heap.Push(&reservoir, weightedEntry{Entry: Entry{Key: k, Value: v}, score: score})
+		} else if score > reservoir[0].score {
+			reservoir[0] = weightedEntry{Entry: Entry{Key: k, Value: v}, score: score}
+			heap.Fix(&reservoir, 0)
+		}
+	}
+	entries := make([]Entry, len(reservoir))
+	for i, item := range reservoir {
+		entries[i] = item.Entry
+	}
+	return entries
+}
+
+// weightedEntry is an entry with its random score for weighted sampling.
+type weightedEntry struct {
+	Entry
+	score float64
+}
+
+// weightedEntryHeap is a min-heap of weightedEntry by score, implementing heap.Interface.
+type weightedEntryHeap []weightedEntry
+
+func (h weightedEntryHeap) Len() int            { return len(h) }
+func (h weightedEntryHeap) Less(i, j int) bool  { return h[i].score < h[j].score }
+func (h weightedEntryHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *weightedEntryHeap) Push(x interface{}) { *h = append(*h, x.(weightedEntry)) }
+func (h *weightedEntryHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
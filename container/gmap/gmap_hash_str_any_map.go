@@ -61,6 +61,17 @@ func (m *StrAnyMap) Clone() *StrAnyMap {
 	return NewStrAnyMapFrom(m.MapCopy(), m.mu.IsSafe())
 }
 
+// ToMap converts the map to a new Map with the same concurrent-safety.
+func (m *StrAnyMap) ToMap() *Map {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	data := make(map[interface{}]interface{}, len(m.data))
+	for k, v := range m.data {
+		data[k] = v
+	}
+	return NewFrom(data, m.mu.IsSafe())
+}
+
 // Map returns the underlying data map.
 // Note that, if it's in concurrent-safe usage, it returns a copy of underlying data,
 // or else a pointer to the underlying data.
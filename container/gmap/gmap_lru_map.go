@@ -0,0 +1,224 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with gm file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gmap
+
+import (
+	"github.com/gogf/gf/v2/container/glist"
+	"github.com/gogf/gf/v2/internal/rwmutex"
+)
+
+// LRUMap is a map with limited capacity that evicts the least-recently-used item
+// when the capacity is exceeded.
+//
+// It is backed by a hash table to store values and doubly-linked list to store recency,
+// in which the front is the most-recently-used item and the back is the least-recently-used one.
+// Both of them are changed within the same lock to stay consistent.
+type LRUMap struct {
+	mu       rwmutex.RWMutex
+	data     map[interface{}]*glist.Element
+	list     *glist.List
+	capacity int
+	onEvict  func(key, value interface{})
+}
+
+// NewLRUMap creates and returns an empty LRU map with given `capacity`.
+// The map has no capacity limit if `capacity` <= 0.
+// The parameter `safe` is used to specify whether using map in concurrent-safety,
+// which is false in default.
+func NewLRUMap(capacity int, safe ...bool) *LRUMap {
+	return &LRUMap{
+		mu:       rwmutex.Create(safe...),
+		data:     make(map[interface{}]*glist.Element),
+		list:     glist.New(),
+		capacity: capacity,
+	}
+}
+
+// OnEvict sets the callback function `f` which is called with the key and value of each item
+// evicted because of the capacity limit. Note that items deleted by Remove or Clear are not evicted.
+//
+// The callback function `f` is called after the lock of the map is released,
+// so it is safe calling methods of the map in `f`.
+func (m *LRUMap) OnEvict(f func(key, value interface{})) {
+	m.mu.Lock()
+	m.onEvict = f
+	m.mu.Unlock()
+}
+
+// Set sets key-value to the map and marks the `key` as the most-recently-used one.
+// It evicts the least-recently-used item if the size of the map exceeds its capacity.
+func (m *LRUMap) Set(key interface{}, value interface{}) {
+	m.mu.Lock()
+	m.doSet(key, value)
+	evicted, onEvict := m.doEvict(), m.onEvict
+	m.mu.Unlock()
+	m.notifyEvicted(onEvict, evicted)
+}
+
+// Sets batch sets key-values to the map.
+func (m *LRUMap) Sets(data map[interface{}]interface{}) {
+	m.mu.Lock()
+	for key, value := range data {
+		m.doSet(key, value)
+	}
+	evicted, onEvict := m.doEvict(), m.onEvict
+	m.mu.Unlock()
+	m.notifyEvicted(onEvict, evicted)
+}
+
+// Search searches the map with given `key` and marks the `key` as the most-recently-used one if found.
+// Second return parameter `found` is true if key was found, otherwise false.
+func (m *LRUMap) Search(key interface{}) (value interface{}, found bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.data[key]; ok {
+		m.list.MoveToFront(e)
+		return e.Value.(*gListMapNode).value, true
+	}
+	return nil, false
+}
+
+// Get returns the value by given `key` and marks the `key` as the most-recently-used one if found.
+func (m *LRUMap) Get(key interface{}) (value interface{}) {
+	value, _ = m.Search(key)
+	return
+}
+
+// Peek returns the value by given `key` without changing its recency.
+func (m *LRUMap) Peek(key interface{}) (value interface{}) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if e, ok := m.data[key]; ok {
+		value = e.Value.(*gListMapNode).value
+	}
+	return
+}
+
+// Contains checks whether a key exists without changing its recency.
+// It returns true if the `key` exists, or else false.
+func (m *LRUMap) Contains(key interface{}) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, ok := m.data[key]
+	return ok
+}
+
+// Remove deletes value from map by given `key`, and return this deleted value.
+func (m *LRUMap) Remove(key interface{}) (value interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e, ok := m.data[key]; ok {
+		value = e.Value.(*gListMapNode).value
+		delete(m.data, key)
+		m.list.Remove(e)
+	}
+	return
+}
+
+// Keys returns all keys of the map as a slice,
+// from the most-recently-used one to the least-recently-used one.
+func (m *LRUMap) Keys() []interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.list == nil {
+		return []interface{}{}
+	}
+	var (
+		keys  = make([]interface{}, m.list.Len())
+		index = 0
+	)
+	m.list.IteratorAsc(func(e *glist.Element) bool {
+		keys[index] = e.Value.(*gListMapNode).key
+		index++
+		return true
+	})
+	return keys
+}
+
+// Iterator iterates the map readonly from the most-recently-used item to the least-recently-used one
+// with custom callback function `f`. It does not change the recency of the items.
+// If `f` returns true, then it continues iterating; or false to stop.
+func (m *LRUMap) Iterator(f func(key, value interface{}) bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.list == nil {
+		return
+	}
+	var node *gListMapNode
+	m.list.IteratorAsc(func(e *glist.Element) bool {
+		node = e.Value.(*gListMapNode)
+		return f(node.key, node.value)
+	})
+}
+
+// Size returns the size of the map.
+func (m *LRUMap) Size() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.data)
+}
+
+// Cap returns the capacity of the map.
+func (m *LRUMap) Cap() int {
+	return m.capacity
+}
+
+// IsEmpty checks whether the map is empty.
+// It returns true if map is empty, or else false.
+func (m *LRUMap) IsEmpty() bool {
+	return m.Size() == 0
+}
+
+// Clear deletes all data of the map.
+func (m *LRUMap) Clear() {
+	m.mu.Lock()
+	m.data = make(map[interface{}]*glist.Element)
+	m.list = glist.New()
+	m.mu.Unlock()
+}
+
+// doSet sets key-value to the map and marks the `key` as the most-recently-used one without mutex.
+func (m *LRUMap) doSet(key interface{}, value interface{}) {
+	if m.data == nil {
+		m.data = make(map[interface{}]*glist.Element)
+		m.list = glist.New()
+	}
+	if e, ok := m.data[key]; ok {
+		e.Value.(*gListMapNode).value = value
+		m.list.MoveToFront(e)
+		return
+	}
+	m.data[key] = m.list.PushFront(&gListMapNode{key, value})
+}
+
+// doEvict deletes the least-recently-used items without mutex until the size of the map
+// does not exceed its capacity, and returns the deleted items.
+func (m *LRUMap) doEvict() (evicted []*gListMapNode) {
+	if m.capacity <= 0 {
+		return nil
+	}
+	for len(m.data) > m.capacity {
+		var (
+			e    = m.list.Back()
+			node = e.Value.(*gListMapNode)
+		)
+		m.list.Remove(e)
+		delete(m.data, node.key)
+		evicted = append(evicted, node)
+	}
+	return
+}
+
+// notifyEvicted calls the eviction callback `onEvict` with each of `evicted` items.
+func (m *LRUMap) notifyEvicted(onEvict func(key, value interface{}), evicted []*gListMapNode) {
+	if onEvict == nil {
+		return
+	}
+	for _, node := range evicted {
+		onEvict(node.key, node.value)
+	}
+}
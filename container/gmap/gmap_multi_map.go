@@ -0,0 +1,139 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with gm file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gmap
+
+import (
+	"github.com/gogf/gf/v2/internal/rwmutex"
+)
+
+// MultiMap is a map of which each key holds a slice of values, which are appended in order.
+type MultiMap struct {
+	mu   rwmutex.RWMutex
+	data map[interface{}][]interface{}
+}
+
+// NewMultiMap creates and returns an empty multi map.
+// The parameter `safe` is used to specify whether using map in concurrent-safety,
+// which is false in default.
+func NewMultiMap(safe ...bool) *MultiMap {
+	return &MultiMap{
+		mu:   rwmutex.Create(safe...),
+		data: make(map[interface{}][]interface{}),
+	}
+}
+
+// Append appends `values` to the values of `key`, which creates the key if it does not exist.
+func (m *MultiMap) Append(key interface{}, values ...interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.data == nil {
+		m.data = make(map[interface{}][]interface{})
+	}
+	m.data[key] = append(m.data[key], values...)
+}
+
+// GetSlice returns a copy of the values of `key` in appended order, or nil if `key` does not exist.
+func (m *MultiMap) GetSlice(key interface{}) []interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	values, ok := m.data[key]
+	if !ok {
+		return nil
+	}
+	return append(make([]interface{}, 0, len(values)), values...)
+}
+
+// Count returns the count of values of `key`.
+func (m *MultiMap) Count(key interface{}) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.data[key])
+}
+
+// Contains checks whether a key exists.
+// It returns true if the `key` exists, or else false.
+func (m *MultiMap) Contains(key interface{}) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, ok := m.data[key]
+	return ok
+}
+
+// RemoveValue deletes the first value of `key` that equals to `value` using reflect.DeepEqual,
+// and returns true if it is deleted. The key is deleted if it has no value anymore.
+func (m *MultiMap) RemoveValue(key interface{}, value interface{}) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	values := m.data[key]
+	for i, v := range values {
+		if !valuesEqual(v, value) {
+			continue
+		}
+		if len(values) == 1 {
+			delete(m.data, key)
+		} else {
+			m.data[key] = append(values[:i:i], values[i+1:]...)
+		}
+		return true
+	}
+	return false
+}
+
+// Remove deletes `key` with all its values from the map, and returns the deleted values.
+func (m *MultiMap) Remove(key interface{}) []interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	values, ok := m.data[key]
+	if ok {
+		delete(m.data, key)
+	}
+	return values
+}
+
+// Keys returns all keys of the map as a slice.
+func (m *MultiMap) Keys() []interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	keys := make([]interface{}, 0, len(m.data))
+	for key := range m.data {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Iterator iterates the map readonly with custom callback function `f`,
+// in which `values` are the values of `key` that should not be changed in `f`.
+// If `f` returns true, then it continues iterating; or false to stop.
+func (m *MultiMap) Iterator(f func(key interface{}, values []interface{}) bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for k, v := range m.data {
+		if !f(k, v) {
+			break
+		}
+	}
+}
+
+// Size returns the count of keys of the map.
+func (m *MultiMap) Size() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.data)
+}
+
+// IsEmpty checks whether the map is empty.
+// It returns true if map is empty, or else false.
+func (m *MultiMap) IsEmpty() bool {
+	return m.Size() == 0
+}
+
+// Clear deletes all data of the map.
+func (m *MultiMap) Clear() {
+	m.mu.Lock()
+	m.data = make(map[interface{}][]interface{})
+	m.mu.Unlock()
+}
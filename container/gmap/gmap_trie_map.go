@@ -0,0 +1,315 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with gm file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gmap
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gogf/gf/v2/internal/rwmutex"
+)
+
+// TrieMap is a map with string keys backed by a radix tree, which supports prefix queries
+// like LongestPrefix and WithPrefix that the hash map cannot answer efficiently.
+// The keys are iterated in lexicographical order.
+type TrieMap struct {
+	mu   rwmutex.RWMutex
+	root *gTrieMapNode
+	size int
+}
+
+// gTrieMapNode is a node of TrieMap, of which the key is the concatenation of
+// the prefixes from the root to the node.
+type gTrieMapNode struct {
+	prefix   string          // prefix is the label of the edge from the parent to the node.
+	children []*gTrieMapNode // children are sorted by the first byte of their prefixes, which are distinct.
+	value    interface{}
+	hasValue bool
+}
+
+// NewTrieMap creates and returns an empty trie map.
+// The parameter `safe` is used to specify whether using map in concurrent-safety,
+// which is false in default.
+func NewTrieMap(safe ...bool) *TrieMap {
+	return &TrieMap{
+		mu:   rwmutex.Create(safe...),
+		root: &gTrieMapNode{},
+	}
+}
+
+// Set sets key-value to the map.
+func (m *TrieMap) Set(key string, value interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.root == nil {
+		m.root = &gTrieMapNode{}
+	}
+	node := m.root
+	for key != "" {
+		index, found := node.child(key[0])
+		if !found {
+			child := &gTrieMapNode{prefix: key, value: value, hasValue: true}
+			node.children = append(node.children, nil)
+			copy(node.children[index+1:], node.children[index:])
+			node.children[index] = child
+			m.size++
+			return
+		}
+		child := node.children[index]
+		length := commonPrefixLength(key, child.prefix)
+		if length < len(child.prefix) {
+			// Split the edge of the child at the end of the common prefix.
+			middle := &gTrieMapNode{prefix: child.prefix[:length], children: []*gTrieMapNode{child}}
+			child.prefix = child.prefix[length:]
+			node.children[index] = middle
+			child = middle
+		}
+		key = key[length:]
+		node = child
+	}
+	if !node.hasValue {
+		m.size++
+	}
+	node.value = value
+	node.hasValue = true
+}
+
+// Search searches the map with given `key`.
+// Second return parameter `found` is true if key was found, otherwise false.
+func (m *TrieMap) Search(key string) (value interface{}, found bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if node := m.doSearch(key); node != nil && node.hasValue {
+		return node.value, true
+	}
+	return nil, false
+}
+
+// Get returns the value by given `key`.
+func (m *TrieMap) Get(key string) (value interface{}) {
+	value, _ = m.Search(key)
+	return
+}
+
+// Contains checks whether a key exists.
+// It returns true if the `key` exists, or else false.
+func (m *TrieMap) Contains(key string) bool {
+	_, found := m.Search(key)
+	return found
+}
+
+// LongestPrefix returns the longest key in the map that is a prefix of `s` and its value.
+// The returned `found` is false if no key is a prefix of `s`.
+func (m *TrieMap) LongestPrefix(s string) (key string, value interface{}, found bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	node := m.root
+	if node == nil {
+		return "", nil, false
+	}
+	if node.hasValue {
+		value, found = node.value, true
+	}
+	for consumed := 0; consumed < len(s); {
+		index, ok := node.child(s[consumed])
+		if !ok || !strings.HasPrefix(s[consumed:], node.children[index].prefix) {
+			break
+		}
+		node = node.children[index]
+		consumed += len(node.prefix)
+		if node.hasValue {
+			key, value, found = s[:consumed], node.value, true
+		}
+	}
+	return
+}
+
+// WithPrefix returns all the key-values of which the key has prefix `prefix` as a new map.
+func (m *TrieMap) WithPrefix(prefix string) map[string]interface{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	data := make(map[string]interface{})
+	node := m.root
+	if node == nil {
+		return data
+	}
+	path := ""
+	for remaining := prefix; remaining != ""; {
+		index, ok := node.child(remaining[0])
+		if !ok {
+			return data
+		}
+		child := node.children[index]
+		switch {
+		case strings.HasPrefix(remaining, child.prefix):
+			remaining = remaining[len(child.prefix):]
+		case strings.HasPrefix(child.prefix, remaining):
+			remaining = ""
+		default:
+			return data
+		}
+		path += child.prefix
+		node = child
+	}
+	node.walk(path, func(key string, value interface{}) bool {
+		data[key] = value
+		return true
+	})
+	return data
+}
+
+// Remove deletes value from map by given `key`, and return this deleted value.
+func (m *TrieMap) Remove(key string) (value interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.root == nil {
+		return nil
+	}
+	var (
+		node    = m.root
+		parents []*gTrieMapNode
+		indexes []int
+	)
+	for remaining := key; remaining != ""; {
+		index, ok := node.child(remaining[0])
+		if !ok || !strings.HasPrefix(remaining, node.children[index].prefix) {
+			return nil
+		}
+		parents = append(parents, node)
+		indexes = append(indexes, index)
+		remaining = remaining[len(node.children[index].prefix):]
+		node = node.children[index]
+	}
+	if !node.hasValue {
+		return nil
+	}
+	value = node.value
+	node.value, node.hasValue = nil, false
+	m.size--
+	if node == m.root {
+		return
+	}
+	parent := parents[len(parents)-1]
+	if len(node.children) == 0 {
+		index := indexes[len(indexes)-1]
+		parent.children = append(parent.children[:index], parent.children[index+1:]...)
+		if parent != m.root {
+			parent.compress()
+		}
+	} else {
+		node.compress()
+	}
+	return
+}
+
+// Keys returns all keys of the map in lexicographical order.
+func (m *TrieMap) Keys() []string {
+	keys := make([]string, 0, m.Size())
+	m.Iterator(func(key string, value interface{}) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+// Map returns a copy of the underlying data of the map.
+func (m *TrieMap) Map() map[string]interface{} {
+	data := make(map[string]interface{}, m.Size())
+	m.Iterator(func(key string, value interface{}) bool {
+		data[key] = value
+		return true
+	})
+	return data
+}
+
+// Iterator iterates the map readonly in lexicographical order of keys with custom callback function `f`.
+// If `f` returns true, then it continues iterating; or false to stop.
+func (m *TrieMap) Iterator(f func(key string, value interface{}) bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.root != nil {
+		m.root.walk("", f)
+	}
+}
+
+// Size returns the size of the map.
+func (m *TrieMap) Size() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.size
+}
+
+// IsEmpty checks whether the map is empty.
+// It returns true if map is empty, or else false.
+func (m *TrieMap) IsEmpty() bool {
+	return m.Size() == 0
+}
+
+// Clear deletes all data of the map.
+func (m *TrieMap) Clear() {
+	m.mu.Lock()
+	m.root = &gTrieMapNode{}
+	m.size = 0
+	m.mu.Unlock()
+}
+
+// doSearch returns the node of `key` without mutex, or nil if there is no such node.
+func (m *TrieMap) doSearch(key string) *gTrieMapNode {
+	node := m.root
+	for node != nil && key != "" {
+		index, ok := node.child(key[0])
+		if !ok || !strings.HasPrefix(key, node.children[index].prefix) {
+			return nil
+		}
+		key = key[len(node.children[index].prefix):]
+		node = node.children[index]
+	}
+	return node
+}
+
+// child searches the child of which the prefix starts with byte `c`.
+// It returns the index of the child if found, or else the index where it should be inserted.
+func (node *gTrieMapNode) child(c byte) (index int, found bool) {
+	index = sort.Search(len(node.children), func(i int) bool {
+		return node.children[i].prefix[0] >= c
+	})
+	return index, index < len(node.children) && node.children[index].prefix[0] == c
+}
+
+// compress merges the node with its only child if the node has no value.
+func (node *gTrieMapNode) compress() {
+	if node.hasValue || len(node.children) != 1 {
+		return
+	}
+	child := node.children[0]
+	node.prefix += child.prefix
+	node.children = child.children
+	node.value, node.hasValue = child.value, child.hasValue
+}
+
+// walk calls `f` with the key-values of the subtree of the node in lexicographical order,
+// in which `key` is the key of the node. It returns false if `f` returns false.
+func (node *gTrieMapNode) walk(key string, f func(key string, value interface{}) bool) bool {
+	if node.hasValue && !f(key, node.value) {
+		return false
+	}
+	for _, child := range node.children {
+		if !child.walk(key+child.prefix, f) {
+			return false
+		}
+	}
+	return true
+}
+
+// commonPrefixLength returns the length of the common prefix of `a` and `b`.
+func commonPrefixLength(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,103 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with gm file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gmap
+
+import (
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+)
+
+// Tx is a transaction on a Map created by Map.Begin, which changes the map directly and records
+// the previous values of the changed keys, so that the changes can be undone by Rollback.
+//
+// Tx itself is not concurrent-safe. Note that Rollback restores the previous values of the keys
+// changed by the transaction, which overwrites the changes of the same keys by others in the meantime.
+type Tx struct {
+	m        *AnyAnyMap
+	undo     []gTxUndo
+	finished bool
+}
+
+// gTxUndo is the previous value of a key changed by Tx.
+type gTxUndo struct {
+	key   interface{}
+	value interface{}
+	found bool
+}
+
+// Begin starts and returns a transaction on the map.
+func (m *AnyAnyMap) Begin() *Tx {
+	return &Tx{m: m}
+}
+
+// Set sets key-value to the map, and records the previous value of `key`.
+func (tx *Tx) Set(key interface{}, value interface{}) {
+	tx.checkFinished()
+	tx.m.checkFrozen()
+	tx.m.mu.Lock()
+	defer tx.m.mu.Unlock()
+	if tx.m.data == nil {
+		tx.m.data = make(map[interface{}]interface{})
+	}
+	oldValue, found := tx.m.data[key]
+	tx.undo = append(tx.undo, gTxUndo{key: key, value: oldValue, found: found})
+	tx.m.doSet(key, value)
+}
+
+// Remove deletes `key` from the map and returns its value, and records the previous value of `key`.
+func (tx *Tx) Remove(key interface{}) (value interface{}) {
+	tx.checkFinished()
+	tx.m.checkFrozen()
+	tx.m.mu.Lock()
+	defer tx.m.mu.Unlock()
+	value, found := tx.m.doRemove(key)
+	if found {
+		tx.undo = append(tx.undo, gTxUndo{key: key, value: value, found: true})
+	}
+	return
+}
+
+// Commit finishes the transaction and keeps the changes.
+// It does nothing if the transaction is already finished.
+func (tx *Tx) Commit() {
+	tx.finished = true
+	tx.undo = nil
+}
+
+// Rollback finishes the transaction and restores the previous values of the changed keys
+// within one RWMutex.Lock, in which the keys not existing before are deleted.
+// It does nothing if the transaction is already finished.
+func (tx *Tx) Rollback() {
+	if tx.finished {
+		return
+	}
+	tx.finished = true
+	if len(tx.undo) == 0 {
+		return
+	}
+	tx.m.checkFrozen()
+	tx.m.mu.Lock()
+	defer tx.m.mu.Unlock()
+	if tx.m.data == nil {
+		tx.m.data = make(map[interface{}]interface{})
+	}
+	for i := len(tx.undo) - 1; i >= 0; i-- {
+		if undo := tx.undo[i]; undo.found {
+			tx.m.doSet(undo.key, undo.value)
+		} else {
+			tx.m.doRemove(undo.key)
+		}
+	}
+	tx.undo = nil
+}
+
+// checkFinished panics if the transaction is finished by Commit or Rollback.
+func (tx *Tx) checkFinished() {
+	if tx.finished {
+		panic(gerror.NewCode(gcode.CodeInvalidOperation, `transaction is already finished`))
+	}
+}
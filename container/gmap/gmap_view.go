@@ -0,0 +1,116 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with gm file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gmap
+
+import (
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+)
+
+// MapView is a read-only view of a frozen Map returned by Map.View, which reads the data of the map
+// without any lock. It is used for the map that is loaded once and then read frequently.
+//
+// All the methods of MapView panic if the map is changed after the view is created,
+// which never happens unless a change of the map is in progress concurrently when the map is frozen.
+type MapView struct {
+	source  *AnyAnyMap
+	data    map[interface{}]interface{}
+	version int64
+}
+
+// View returns a read-only view of the map reading the data without any lock.
+// It panics if the map is not frozen by Freeze.
+func (m *AnyAnyMap) View() MapView {
+	if !m.frozen.Val() {
+		panic(gerror.NewCode(gcode.CodeInvalidOperation, `cannot create view of the map that is not frozen`))
+	}
+	// It waits for the change in progress within RWMutex.Lock before the map is frozen.
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return MapView{
+		source:  m,
+		data:    m.data,
+		version: m.version.Val(),
+	}
+}
+
+// check panics if the source map is changed after the view is created.
+func (v MapView) check() {
+	if v.source != nil && v.source.version.Val() != v.version {
+		panic(gerror.NewCode(gcode.CodeInvalidOperation, `map is changed after the view is created`))
+	}
+}
+
+// Search searches the map with given `key`.
+// Second return parameter `found` is true if key was found, otherwise false.
+func (v MapView) Search(key interface{}) (value interface{}, found bool) {
+	v.check()
+	value, found = v.data[key]
+	return
+}
+
+// Get returns the value by given `key`.
+func (v MapView) Get(key interface{}) interface{} {
+	v.check()
+	return v.data[key]
+}
+
+// Contains checks whether a key exists.
+// It returns true if the `key` exists, or else false.
+func (v MapView) Contains(key interface{}) bool {
+	v.check()
+	_, ok := v.data[key]
+	return ok
+}
+
+// Keys returns all keys of the map as a slice.
+func (v MapView) Keys() []interface{} {
+	v.check()
+	keys := make([]interface{}, 0, len(v.data))
+	for key := range v.data {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Values returns all values of the map as a slice.
+func (v MapView) Values() []interface{} {
+	v.check()
+	values := make([]interface{}, 0, len(v.data))
+	for _, value := range v.data {
+		values = append(values, value)
+	}
+	return values
+}
+
+// Size returns the size of the map.
+func (v MapView) Size() int {
+	v.check()
+	return len(v.data)
+}
+
+// Iterator iterates the map readonly with custom callback function `f`.
+// If `f` returns true, then it continues iterating; or false to stop.
+// The iteration order is random, unless it is made deterministic by SetIterationSeed.
+func (v MapView) Iterator(f func(k interface{}, v interface{}) bool) {
+	v.check()
+	if seed, ok := getIterationSeed(); ok {
+		keys := v.Keys()
+		sortKeysBySeed(keys, seed)
+		for _, key := range keys {
+			if !f(key, v.data[key]) {
+				break
+			}
+		}
+		return
+	}
+	for key, value := range v.data {
+		if !f(key, value) {
+			break
+		}
+	}
+}
@@ -7,9 +7,14 @@
 package gmap_test
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/gogf/gf/v2/container/gmap"
+	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/test/gtest"
 	"github.com/gogf/gf/v2/util/gutil"
 )
@@ -188,3 +193,193 @@ func Test_Map_Basic_Merge(t *testing.T) {
 		t.Assert(m1.Map(), map[interface{}]interface{}{"key1": "val1", "key2": "val2"})
 	})
 }
+
+func Test_Map_NewFromJSON(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m, err := gmap.NewFromJSON([]byte(`{"a":1,"b":"2","c":{"d":[3]}}`), true)
+		t.AssertNil(err)
+		t.Assert(m.Size(), 3)
+		t.Assert(m.Get("a"), 1)
+		t.Assert(m.Get("b"), "2")
+		c, ok := m.Get("c").(map[string]interface{})
+		t.Assert(ok, true)
+		t.Assert(c["d"], []interface{}{3})
+
+		b, err := m.MarshalJSON()
+		t.AssertNil(err)
+		m2, err := gmap.NewFromJSON(b)
+		t.AssertNil(err)
+		t.Assert(m2.Map(), m.Map())
+	})
+	gtest.C(t, func(t *gtest.T) {
+		m, err := gmap.NewFromJSON([]byte(`{}`))
+		t.AssertNil(err)
+		t.Assert(m.Size(), 0)
+		m.Set(1, 1)
+		t.Assert(m.Get(1), 1)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		for _, s := range []string{`[1,2]`, `1`, `"a"`, `null`, `{`, ``} {
+			m, err := gmap.NewFromJSON([]byte(s))
+			t.AssertNE(err, nil)
+			t.Assert(m, nil)
+		}
+	})
+}
+
+func Test_Map_NewFromJSONStream(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m, err := gmap.NewFromJSONStream(strings.NewReader(`{"a":1,"b":"2","c":{"d":[3]},"e":null}`), true)
+		t.AssertNil(err)
+		t.Assert(m.Size(), 4)
+		t.Assert(m.Get("a"), 1)
+		t.Assert(m.Get("b"), "2")
+		t.Assert(m.Get("c"), map[string]interface{}{"d": []interface{}{3}})
+		t.Assert(m.Contains("e"), true)
+
+		m, err = gmap.NewFromJSONStream(strings.NewReader(` {} `))
+		t.AssertNil(err)
+		t.Assert(m.Size(), 0)
+		m.Set(1, 1)
+		t.Assert(m.Get(1), 1)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		for _, s := range []string{`[1,2]`, `1`, `"a"`, `null`, `{`, ``, `{"a":}`, `{"a":1`, `{"a" 1}`} {
+			m, err := gmap.NewFromJSONStream(strings.NewReader(s))
+			t.AssertNE(err, nil)
+			t.Assert(m, nil)
+		}
+	})
+}
+
+func Test_Map_SetIterationSeed(t *testing.T) {
+	iterate := func(m *gmap.Map) []interface{} {
+		var keys []interface{}
+		m.Iterator(func(k interface{}, v interface{}) bool {
+			keys = append(keys, k)
+			return true
+		})
+		return keys
+	}
+	gtest.C(t, func(t *gtest.T) {
+		defer gmap.ResetIterationSeed()
+		data := make(map[interface{}]interface{})
+		for i := 0; i < 100; i++ {
+			data[i] = i
+			data[fmt.Sprint("key", i)] = i
+		}
+		gmap.SetIterationSeed(1)
+		var (
+			m1    = gmap.NewFrom(data)
+			m2    = m1.Clone(true)
+			keys1 = iterate(m1)
+		)
+		t.Assert(len(keys1), 200)
+		for i := 0; i < 5; i++ {
+			t.Assert(iterate(m1), keys1)
+			t.Assert(iterate(m2), keys1)
+		}
+		var snapshotKeys []interface{}
+		m1.IteratorSnapshot(func(k interface{}, v interface{}) bool {
+			snapshotKeys = append(snapshotKeys, k)
+			t.Assert(v, data[k])
+			return true
+		})
+		t.Assert(snapshotKeys, keys1)
+
+		gmap.SetIterationSeed(2)
+		t.AssertNE(iterate(m1), keys1)
+		gmap.SetIterationSeed(1)
+		t.Assert(iterate(m1), keys1)
+
+		gmap.ResetIterationSeed()
+		t.Assert(len(iterate(m1)), 200)
+	})
+}
+
+func Test_Map_NewFromArray(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewFromArray(g.Slice{"a", "b", "c"}, g.Slice{1, 2})
+		t.Assert(m.Map(), g.MapAnyAny{"a": 1, "b": 2, "c": nil})
+		m = gmap.NewFromArray(g.Slice{"a"}, g.Slice{1, 2}, true)
+		t.Assert(m.Map(), g.MapAnyAny{"a": 1})
+		t.Assert(gmap.NewFromArray(nil, g.Slice{1}).Size(), 0)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewFromArrayWithDefault(g.Slice{"a", "b", "c", "a"}, g.Slice{1, nil}, 0)
+		t.Assert(m.Map(), g.MapAnyAny{"a": 0, "b": nil, "c": 0})
+		m = gmap.NewFromArrayWithDefault(g.Slice{1, 2}, nil, "", true)
+		t.Assert(m.Map(), g.MapAnyAny{1: "", 2: ""})
+	})
+}
+
+func Test_Set(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		s := gmap.NewSet(true)
+		s.Add(1, 2, 3)
+		t.Assert(s.Size(), 3)
+		t.Assert(s.Contains(1), true)
+		s.Remove(1)
+		t.Assert(s.Contains(1), false)
+
+		var (
+			s1 = gmap.NewSetFrom(g.Slice{1, 2, 3})
+			s2 = gmap.NewSetFrom(g.Slice{2, 3, 4})
+		)
+		union := s1.Union(s2).Slice()
+		sort.Slice(union, func(i, j int) bool { return union[i].(int) < union[j].(int) })
+		t.Assert(union, g.Slice{1, 2, 3, 4})
+		t.Assert(s1.Intersect(s2).Size(), 2)
+		t.Assert(s1.Diff(s2).Slice(), g.Slice{1})
+	})
+}
+
+func Test_Transfer(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			active   = gmap.NewFrom(g.MapAnyAny{1: "a", 2: "b", 3: "c"}, true)
+			archived = gmap.New(true)
+		)
+		t.Assert(gmap.Transfer(active, archived, g.Slice{1, 3, 4}), 2)
+		t.Assert(active.Map(), g.MapAnyAny{2: "b"})
+		t.Assert(archived.Map(), g.MapAnyAny{1: "a", 3: "c"})
+
+		archived.Set(2, "old")
+		t.Assert(gmap.Transfer(active, archived, g.Slice{2}), 1)
+		t.Assert(active.Size(), 0)
+		t.Assert(archived.Get(2), "b")
+
+		t.Assert(gmap.Transfer(archived, archived, g.Slice{1}), 0)
+		t.Assert(gmap.Transfer(nil, archived, g.Slice{1}), 0)
+		t.Assert(archived.Size(), 3)
+	})
+	// Transferring in both directions concurrently keeps every key in exactly one map.
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			m1   = gmap.New(true)
+			m2   = gmap.New(true)
+			keys = make([]interface{}, 100)
+			wg   sync.WaitGroup
+		)
+		for i := range keys {
+			keys[i] = i
+			m1.Set(i, i)
+		}
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				gmap.Transfer(m1, m2, keys)
+			}()
+			go func() {
+				defer wg.Done()
+				gmap.Transfer(m2, m1, keys)
+			}()
+		}
+		wg.Wait()
+		t.Assert(m1.Size()+m2.Size(), 100)
+		for _, key := range keys {
+			t.Assert(m1.Contains(key) != m2.Contains(key), true)
+		}
+	})
+}
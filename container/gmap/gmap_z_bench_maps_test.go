@@ -9,6 +9,7 @@
 package gmap_test
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/gogf/gf/v2/container/gmap"
@@ -80,3 +81,33 @@ func Benchmark_TreeMap_Get(b *testing.B) {
 		}
 	})
 }
+
+func Benchmark_AnyAnyMap_Equal(b *testing.B) {
+	var (
+		m1 = gmap.New()
+		m2 = gmap.New()
+	)
+	for i := 0; i < 1000; i++ {
+		m1.Set(i, i)
+		m2.Set(i, i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m1.Equal(m2)
+	}
+}
+
+func Benchmark_AnyAnyMap_EqualFunc_DeepEqual(b *testing.B) {
+	var (
+		m1 = gmap.New()
+		m2 = gmap.New()
+	)
+	for i := 0; i < 1000; i++ {
+		m1.Set(i, i)
+		m2.Set(i, i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		m1.EqualFunc(m2, reflect.DeepEqual)
+	}
+}
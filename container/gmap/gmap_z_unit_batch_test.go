@@ -0,0 +1,77 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with gm file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gmap_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/gogf/gf/v2/container/gmap"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+func Test_Batch_Apply(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewFrom(g.MapAnyAny{"a": 1, "b": 2}, true)
+		b := gmap.NewBatch().Set("c", 3).Remove("a").Set("a", 10).Remove("z")
+		t.Assert(b.Len(), 4)
+		t.AssertNil(b.Apply(m))
+		t.Assert(m.Map(), g.MapAnyAny{"a": 10, "b": 2, "c": 3})
+
+		var empty gmap.Map
+		t.AssertNil(b.Apply(&empty))
+		t.Assert(empty.Map(), g.MapAnyAny{"a": 10, "c": 3})
+	})
+	// Nothing is applied if any precondition fails.
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewFrom(g.MapAnyAny{"balance": 100}, true)
+		b := new(gmap.Batch).
+			Require("balance", func(value interface{}, found bool) bool {
+				return found && value.(int) >= 150
+			}).
+			Set("balance", -50).
+			Set("log", "withdraw 150")
+		t.AssertNE(b.Apply(m), nil)
+		t.Assert(m.Map(), g.MapAnyAny{"balance": 100})
+
+		b = new(gmap.Batch).
+			Require("log", func(value interface{}, found bool) bool {
+				return !found
+			}).
+			Set("balance", 50).
+			Set("log", "withdraw 50")
+		t.AssertNil(b.Apply(m))
+		t.Assert(m.Map(), g.MapAnyAny{"balance": 50, "log": "withdraw 50"})
+		t.AssertNE(b.Apply(m), nil)
+	})
+	// Optimistic transaction by concurrent appliers.
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			m       = gmap.NewFrom(g.MapAnyAny{"version": 0}, true)
+			wg      sync.WaitGroup
+			applied = make(chan struct{}, 100)
+		)
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				b := new(gmap.Batch).
+					Require("version", func(value interface{}, found bool) bool {
+						return value == 0
+					}).
+					Set("version", 1)
+				if b.Apply(m) == nil {
+					applied <- struct{}{}
+				}
+			}()
+		}
+		wg.Wait()
+		t.Assert(len(applied), 1)
+		t.Assert(m.Get("version"), 1)
+	})
+}
@@ -0,0 +1,83 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with gm file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gmap_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/gogf/gf/v2/container/gmap"
+	"github.com/gogf/gf/v2/container/gtype"
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+func Test_BoundedMap_Var(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		var m gmap.BoundedMap
+		t.Assert(m.Set(1, 1), true)
+		t.Assert(m.Get(1), 1)
+		t.Assert(m.Cap(), 0)
+		t.Assert(m.Available(), -1)
+	})
+}
+
+func Test_BoundedMap_Basic(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewBoundedMap(2)
+		t.Assert(m.IsEmpty(), true)
+		t.Assert(m.Cap(), 2)
+		t.Assert(m.Available(), 2)
+
+		t.Assert(m.Set(1, "a"), true)
+		t.Assert(m.Set(2, "b"), true)
+		t.Assert(m.Available(), 0)
+
+		// Adding new key is rejected, but overwriting is allowed.
+		t.Assert(m.Set(3, "c"), false)
+		t.Assert(m.Contains(3), false)
+		t.Assert(m.Set(1, "A"), true)
+		t.Assert(m.Map(), map[interface{}]interface{}{1: "A", 2: "b"})
+
+		t.Assert(m.Remove(2), "b")
+		t.Assert(m.Remove(2), nil)
+		t.Assert(m.Available(), 1)
+		t.Assert(m.Set(3, "c"), true)
+		t.Assert(m.Size(), 2)
+		t.Assert(len(m.Keys()), 2)
+
+		m.Clear()
+		t.Assert(m.Available(), 2)
+	})
+}
+
+func Test_BoundedMap_Concurrent(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			m     = gmap.NewBoundedMap(10, true)
+			wg    sync.WaitGroup
+			added = gtype.NewInt()
+		)
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				if m.Set(i, i) {
+					added.Add(1)
+				}
+			}(i)
+		}
+		wg.Wait()
+		t.Assert(added.Val(), 10)
+		t.Assert(m.Size(), 10)
+		count := 0
+		m.Iterator(func(key, value interface{}) bool {
+			count++
+			return true
+		})
+		t.Assert(count, 10)
+	})
+}
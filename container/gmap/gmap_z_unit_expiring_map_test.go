@@ -0,0 +1,93 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with gm file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gmap_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/container/gmap"
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+func Test_ExpiringMap_Var(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		var m gmap.ExpiringMap
+		m.Set(1, 1)
+		t.Assert(m.Get(1), 1)
+		t.Assert(m.Size(), 1)
+		m.Close()
+		m.Close()
+	})
+}
+
+func Test_ExpiringMap_Basic(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		// The un-concurrent-safe map deletes the expired items only lazily.
+		m := gmap.NewExpiringMap()
+		defer m.Close()
+		m.Set(1, 1)
+		m.SetWithTTL(2, 2, 50*time.Millisecond)
+		m.SetWithTTL(3, 3, time.Hour)
+
+		t.Assert(m.Get(1), 1)
+		t.Assert(m.Get(2), 2)
+		t.Assert(m.Contains(3), true)
+		t.Assert(m.Size(), 3)
+		t.AssertIN(2, m.Keys())
+
+		ttl, found := m.TTL(1)
+		t.Assert(found, true)
+		t.Assert(ttl, time.Duration(0))
+		ttl, found = m.TTL(3)
+		t.Assert(found, true)
+		t.Assert(ttl > 59*time.Minute, true)
+
+		time.Sleep(100 * time.Millisecond)
+		t.AssertNI(2, m.Keys())
+		_, found = m.TTL(2)
+		t.Assert(found, false)
+		t.Assert(m.Size(), 3)
+
+		v, found := m.Search(2)
+		t.Assert(found, false)
+		t.Assert(v, nil)
+		t.Assert(m.Size(), 2)
+
+		t.Assert(m.Remove(3), 3)
+		t.Assert(m.Remove(3), nil)
+		t.Assert(m.Size(), 1)
+
+		m.Clear()
+		t.Assert(m.Size(), 0)
+	})
+}
+
+func Test_ExpiringMap_Sweep(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewExpiringMap()
+		defer m.Close()
+		m.SetWithTTL(1, 1, 50*time.Millisecond)
+		m.SetWithTTL(2, 2, 50*time.Millisecond)
+		m.Set(3, 3)
+		t.Assert(m.Sweep(), 0)
+		time.Sleep(100 * time.Millisecond)
+		t.Assert(m.Sweep(), 2)
+		t.Assert(m.Size(), 1)
+		t.Assert(m.Remove(1), nil)
+	})
+	// Background sweeper of the concurrent-safe map.
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewExpiringMap(true)
+		defer m.Close()
+		m.SetWithTTL(1, 1, 50*time.Millisecond)
+		m.Set(2, 2)
+		time.Sleep(1500 * time.Millisecond)
+		t.Assert(m.Size(), 1)
+		t.Assert(m.Get(2), 2)
+	})
+}
@@ -0,0 +1,53 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with gm file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gmap_test
+
+import (
+	"testing"
+
+	"github.com/gogf/gf/v2/container/gmap"
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+func Test_HashKey(t *testing.T) {
+	type point struct {
+		X, Y int
+	}
+	gtest.C(t, func(t *gtest.T) {
+		keys := []interface{}{
+			"", "a", "abc", true, false,
+			0, 1, -1, int8(-8), int16(16), int32(-32), int64(64),
+			uint(1), uint8(8), uint16(16), uint32(32), uint64(1 << 63), uintptr(10),
+			1.5, point{1, 2}, nil,
+		}
+		for _, key := range keys {
+			t.Assert(gmap.HashKey(key), gmap.HashKey(key))
+		}
+		// Equal keys created separately.
+		t.Assert(gmap.HashKey("a"+"bc"), gmap.HashKey("abc"))
+		t.Assert(gmap.HashKey(point{1, 2}), gmap.HashKey(point{1, 2}))
+		t.Assert(gmap.HashKey(int64(100)), gmap.HashKey(int64(100)))
+
+		// Different keys are distributed.
+		t.AssertNE(gmap.HashKey(1), gmap.HashKey(2))
+		t.AssertNE(gmap.HashKey("a"), gmap.HashKey("b"))
+		t.AssertNE(gmap.HashKey(true), gmap.HashKey(false))
+		t.AssertNE(gmap.HashKey(point{1, 2}), gmap.HashKey(point{2, 1}))
+	})
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			shards = make([]int, 8)
+			count  = 8000
+		)
+		for i := 0; i < count; i++ {
+			shards[gmap.HashKey(i)%uint64(len(shards))]++
+		}
+		for _, n := range shards {
+			t.Assert(n > count/len(shards)/2, true)
+		}
+	})
+}
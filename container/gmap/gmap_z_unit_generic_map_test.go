@@ -0,0 +1,109 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with gm file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gmap_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/gogf/gf/v2/container/gmap"
+	"github.com/gogf/gf/v2/internal/json"
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+func Test_GMap_Var(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		var m gmap.GMap[string, int]
+		m.Set("a", 1)
+		t.Assert(m.Get("a"), 1)
+		t.Assert(m.Size(), 1)
+	})
+}
+
+func Test_GMap_Basic(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewGMap[string, int](true)
+		m.Set("a", 1)
+		m.Sets(map[string]int{"b": 2, "c": 3})
+		t.Assert(m.Size(), 3)
+		t.Assert(m.IsEmpty(), false)
+
+		var v int = m.Get("a")
+		t.Assert(v, 1)
+		t.Assert(m.Get("z"), 0)
+		v, found := m.Search("b")
+		t.Assert(v, 2)
+		t.Assert(found, true)
+		_, found = m.Search("z")
+		t.Assert(found, false)
+		t.Assert(m.Contains("c"), true)
+
+		keys := m.Keys()
+		sort.Strings(keys)
+		t.Assert(keys, []string{"a", "b", "c"})
+		values := m.Values()
+		sort.Ints(values)
+		t.Assert(values, []int{1, 2, 3})
+
+		t.Assert(m.Remove("a"), 1)
+		t.Assert(m.Remove("a"), 0)
+		m.Removes([]string{"b"})
+		t.Assert(m.Map(), map[string]int{"c": 3})
+
+		m.Clear()
+		t.Assert(m.IsEmpty(), true)
+	})
+}
+
+func Test_GMap_GetOrSet(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewGMap[int, string]()
+		t.Assert(m.GetOrSet(1, "a"), "a")
+		t.Assert(m.GetOrSet(1, "b"), "a")
+		t.Assert(m.GetOrSetFunc(2, func() string { return "b" }), "b")
+		t.Assert(m.GetOrSetFunc(2, func() string { return "c" }), "b")
+		t.Assert(m.GetOrSetFuncLock(3, func() string { return "c" }), "c")
+		t.Assert(m.GetOrSetFuncLock(3, func() string { return "d" }), "c")
+		t.Assert(m.SetIfNotExist(3, "d"), false)
+		t.Assert(m.SetIfNotExist(4, "d"), true)
+		t.Assert(m.Map(), map[int]string{1: "a", 2: "b", 3: "c", 4: "d"})
+	})
+}
+
+func Test_GMap_Iterator_Clone(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewGMapFrom(map[string]int{"a": 1, "b": 2}, true)
+		sum := 0
+		m.Iterator(func(k string, v int) bool {
+			sum += v
+			return true
+		})
+		t.Assert(sum, 3)
+
+		c := m.Clone()
+		c.Set("c", 3)
+		t.Assert(m.Size(), 2)
+		t.Assert(c.Size(), 3)
+
+		m.LockFunc(func(data map[string]int) {
+			data["a"] = 10
+		})
+		m.RLockFunc(func(data map[string]int) {
+			t.Assert(data["a"], 10)
+		})
+	})
+}
+
+func Test_GMap_Json(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewGMapFrom(map[int]string{1: "a", 2: "b"})
+		b, err := json.Marshal(m)
+		t.AssertNil(err)
+		t.Assert(b, `{"1":"a","2":"b"}`)
+		t.Assert(m.String(), `{"1":"a","2":"b"}`)
+	})
+}
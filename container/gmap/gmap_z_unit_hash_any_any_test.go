@@ -7,11 +7,21 @@
 package gmap_test
 
 import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"os"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/gogf/gf/v2/container/garray"
 	"github.com/gogf/gf/v2/container/gmap"
+	"github.com/gogf/gf/v2/container/gtype"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/internal/json"
 	"github.com/gogf/gf/v2/test/gtest"
@@ -427,3 +437,1875 @@ func Test_AnyAnyMap_Diff(t *testing.T) {
 		t.Assert(updatedKeys, []interface{}{3})
 	})
 }
+
+func Test_AnyAnyMap_GetVar_Conversion(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewAnyAnyMapFrom(g.MapAnyAny{
+			"int":    "42",
+			"float":  "3.14",
+			"bool":   "true",
+			"string": 100,
+			"bytes":  "abc",
+		})
+		t.Assert(m.GetVar("int").Int(), 42)
+		t.Assert(m.GetVar("int").Int64(), int64(42))
+		t.Assert(m.GetVar("float").Float64(), 3.14)
+		t.Assert(m.GetVar("bool").Bool(), true)
+		t.Assert(m.GetVar("string").String(), "100")
+		t.Assert(m.GetVar("bytes").Bytes(), []byte("abc"))
+		// Zero values are returned for missing keys or failed conversions.
+		t.Assert(m.GetVar("none").Int(), 0)
+		t.Assert(m.GetVar("none").String(), "")
+		t.Assert(m.GetVar("bytes").Int(), 0)
+		t.Assert(m.GetVar("bytes").Bool(), true)
+	})
+}
+
+func Test_AnyAnyMap_TryLockFunc(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			m     = gmap.NewAnyAnyMapFrom(g.MapAnyAny{1: 1}, true)
+			ch    = make(chan struct{})
+			done  = make(chan struct{})
+			calls = 0
+		)
+		go m.LockFunc(func(data map[interface{}]interface{}) {
+			close(ch)
+			<-done
+		})
+		<-ch
+		t.Assert(m.TryLockFunc(func(data map[interface{}]interface{}) { calls++ }), false)
+		t.Assert(m.TryRLockFunc(func(data map[interface{}]interface{}) { calls++ }), false)
+		t.Assert(calls, 0)
+		close(done)
+		time.Sleep(100 * time.Millisecond)
+
+		t.Assert(m.TryLockFunc(func(data map[interface{}]interface{}) {
+			calls++
+			data[2] = 2
+		}), true)
+		t.Assert(m.TryRLockFunc(func(data map[interface{}]interface{}) {
+			calls++
+			t.Assert(len(data), 2)
+		}), true)
+		t.Assert(calls, 2)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewAnyAnyMap()
+		t.Assert(m.TryLockFunc(func(data map[interface{}]interface{}) {}), true)
+		t.Assert(m.TryRLockFunc(func(data map[interface{}]interface{}) {}), true)
+	})
+}
+
+func Test_AnyAnyMap_LockFuncErr(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewAnyAnyMap(true)
+		err := m.LockFuncErr(func(data map[interface{}]interface{}) error {
+			data[1] = 1
+			return nil
+		})
+		t.AssertNil(err)
+		t.Assert(m.Get(1), 1)
+
+		err = m.LockFuncErr(func(data map[interface{}]interface{}) error {
+			data[2] = 2
+			return gerror.New("failed")
+		})
+		t.Assert(err.Error(), "failed")
+		t.Assert(m.Get(2), 2)
+
+		err = m.LockFuncErr(func(data map[interface{}]interface{}) error {
+			data[3] = 3
+			panic("panicked")
+		})
+		t.AssertNE(err, nil)
+		t.Assert(err.Error(), "panicked")
+		t.Assert(gerror.Code(err), gcode.CodeInternalPanic)
+		// The lock is released after panic.
+		t.Assert(m.Get(3), 3)
+
+		err = m.LockFuncErr(func(data map[interface{}]interface{}) error {
+			panic(gerror.New("panicked error"))
+		})
+		t.Assert(err.Error(), "panicked error")
+	})
+	gtest.C(t, func(t *gtest.T) {
+		var m gmap.AnyAnyMap
+		err := m.LockFuncErr(func(data map[interface{}]interface{}) error {
+			data[1] = 1
+			return nil
+		})
+		t.AssertNil(err)
+		t.Assert(m.Get(1), 1)
+	})
+}
+
+func Test_AnyAnyMap_MergeMaps(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewAnyAnyMapFrom(g.MapAnyAny{1: 1, 2: 2})
+		m.MergeMaps(
+			g.MapAnyAny{2: "2", 3: 3},
+			nil,
+			g.MapAnyAny{3: "3", 4: 4},
+		)
+		t.Assert(m.Map(), g.MapAnyAny{1: 1, 2: "2", 3: "3", 4: 4})
+		m.MergeMaps()
+		t.Assert(m.Size(), 4)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		var m gmap.AnyAnyMap
+		m.MergeMaps(g.MapAnyAny{1: 1})
+		t.Assert(m.Map(), g.MapAnyAny{1: 1})
+	})
+}
+
+func Test_AnyAnyMap_GetOrSetFuncLockX(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewAnyAnyMap(true)
+		v, computed := m.GetOrSetFuncLockX(1, func() interface{} { return 1 })
+		t.Assert(v, 1)
+		t.Assert(computed, true)
+		v, computed = m.GetOrSetFuncLockX(1, func() interface{} { return 2 })
+		t.Assert(v, 1)
+		t.Assert(computed, false)
+
+		v, computed = m.GetOrSetFuncLockX(2, func() interface{} { return nil })
+		t.Assert(v, nil)
+		t.Assert(computed, true)
+		t.Assert(m.Contains(2), false)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			m     = gmap.NewAnyAnyMap(true)
+			wg    = sync.WaitGroup{}
+			count = gtype.NewInt()
+		)
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, computed := m.GetOrSetFuncLockX(1, func() interface{} { return 1 }); computed {
+					count.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+		t.Assert(count.Val(), 1)
+		t.Assert(m.Get(1), 1)
+	})
+}
+
+func Test_AnyAnyMap_IteratorSnapshot(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			m     = gmap.NewAnyAnyMapFrom(g.MapAnyAny{1: 1, 2: 2, 3: 3}, true)
+			items = make(map[interface{}]interface{})
+		)
+		m.IteratorSnapshot(func(k interface{}, v interface{}) bool {
+			// Writing within the callback does not deadlock and does not affect the snapshot.
+			m.Set(k.(int)+10, v)
+			items[k] = v
+			return true
+		})
+		t.Assert(items, g.MapAnyAny{1: 1, 2: 2, 3: 3})
+		t.Assert(m.Size(), 6)
+
+		count := 0
+		m.IteratorSnapshot(func(k interface{}, v interface{}) bool {
+			count++
+			return false
+		})
+		t.Assert(count, 1)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		var m gmap.AnyAnyMap
+		m.IteratorSnapshot(func(k interface{}, v interface{}) bool {
+			t.Error("should not be called")
+			return true
+		})
+	})
+}
+
+func Test_AnyAnyMap_GetOrDefault(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewAnyAnyMapFrom(g.MapAnyAny{1: 1, 2: nil})
+		t.Assert(m.GetOrDefault(1, 100), 1)
+		t.Assert(m.GetOrDefault(2, 100), nil)
+		t.Assert(m.GetOrDefault(3, 100), 100)
+		t.Assert(m.Contains(3), false)
+		t.Assert(m.Size(), 2)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		var m gmap.AnyAnyMap
+		t.Assert(m.GetOrDefault(1, "a"), "a")
+	})
+}
+
+func Test_AnyAnyMap_SetReturning(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewAnyAnyMapFrom(g.MapAnyAny{1: 1, 2: 2})
+		previous := m.SetReturning(g.MapAnyAny{2: 20, 3: 30})
+		t.Assert(previous, g.MapAnyAny{2: 2, 3: nil})
+		t.Assert(m.Map(), g.MapAnyAny{1: 1, 2: 20, 3: 30})
+
+		// Rollback.
+		m.SetReturning(previous)
+		t.Assert(m.Get(2), 2)
+		t.Assert(m.Get(3), nil)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		var m gmap.AnyAnyMap
+		t.Assert(m.SetReturning(g.MapAnyAny{1: 1}), g.MapAnyAny{1: nil})
+		t.Assert(m.Get(1), 1)
+	})
+}
+
+func Test_AnyAnyMap_Count(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewAnyAnyMapFrom(g.MapAnyAny{1: 1, 2: 2, 3: 3, 4: 4}, true)
+		t.Assert(m.Count(func(k interface{}, v interface{}) bool {
+			return v.(int)%2 == 0
+		}), 2)
+		t.Assert(m.Count(func(k interface{}, v interface{}) bool {
+			return true
+		}), 4)
+		t.Assert(m.Count(func(k interface{}, v interface{}) bool {
+			return false
+		}), 0)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		var m gmap.AnyAnyMap
+		t.Assert(m.Count(func(k interface{}, v interface{}) bool {
+			return true
+		}), 0)
+	})
+}
+
+func Test_AnyAnyMap_DoUnderLock(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		var m gmap.AnyAnyMap
+		m.DoUnderLock(func(data map[interface{}]interface{}) {
+			data[1] = 1
+			data[2] = data[1].(int) + 1
+		})
+		t.Assert(m.Map(), g.MapAnyAny{1: 1, 2: 2})
+
+		m.WithData(func(data map[interface{}]interface{}) {
+			delete(data, 1)
+			data[3] = len(data)
+		})
+		t.Assert(m.Map(), g.MapAnyAny{2: 2, 3: 1})
+	})
+}
+
+func Test_AnyAnyMap_Gets(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewAnyAnyMapFrom(g.MapAnyAny{1: 1, 2: 2, 3: nil}, true)
+		t.Assert(m.Gets([]interface{}{2, 4, 1, 3}), []interface{}{2, nil, 1, nil})
+		t.Assert(m.Gets(nil), []interface{}{})
+		t.Assert(m.GetMap([]interface{}{2, 4, 1, 3}), g.MapAnyAny{1: 1, 2: 2, 3: nil})
+		t.Assert(m.GetMap(nil), g.MapAnyAny{})
+	})
+	gtest.C(t, func(t *gtest.T) {
+		var m gmap.AnyAnyMap
+		t.Assert(m.Gets([]interface{}{1}), []interface{}{nil})
+		t.Assert(m.GetMap([]interface{}{1}), g.MapAnyAny{})
+	})
+}
+
+func Test_AnyAnyMap_Transform(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewAnyAnyMapFrom(g.MapAnyAny{1: " a ", 2: "b", 3: 3}, true)
+		m.Transform(func(k interface{}, v interface{}) interface{} {
+			if s, ok := v.(string); ok {
+				return strings.TrimSpace(s)
+			}
+			return v
+		})
+		t.Assert(m.Map(), g.MapAnyAny{1: "a", 2: "b", 3: 3})
+
+		m.Transform(func(k interface{}, v interface{}) interface{} {
+			return k
+		})
+		t.Assert(m.Map(), g.MapAnyAny{1: 1, 2: 2, 3: 3})
+	})
+	gtest.C(t, func(t *gtest.T) {
+		var m gmap.AnyAnyMap
+		m.Transform(func(k interface{}, v interface{}) interface{} {
+			return v
+		})
+		t.Assert(m.Size(), 0)
+	})
+}
+
+func Test_AnyAnyMap_WithPrefix(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewAnyAnyMapFrom(g.MapAnyAny{
+			"db.host":   "127.0.0.1",
+			"db.port":   3306,
+			"cache.ttl": 60,
+			1:           1,
+			10:          10,
+		}, true)
+		t.Assert(m.GetWithPrefix("db."), g.MapAnyAny{"db.host": "127.0.0.1", "db.port": 3306})
+		t.Assert(m.GetWithPrefix("1"), g.MapAnyAny{1: 1, 10: 10})
+		t.Assert(m.GetWithPrefix("none"), g.MapAnyAny{})
+		t.Assert(m.GetWithPrefix(""), m.Map())
+
+		t.Assert(m.RemoveWithPrefix("db."), 2)
+		t.Assert(m.RemoveWithPrefix("db."), 0)
+		t.Assert(m.Map(), g.MapAnyAny{"cache.ttl": 60, 1: 1, 10: 10})
+	})
+	gtest.C(t, func(t *gtest.T) {
+		var m gmap.AnyAnyMap
+		t.Assert(m.GetWithPrefix("a"), g.MapAnyAny{})
+		t.Assert(m.RemoveWithPrefix("a"), 0)
+	})
+}
+
+func Test_AnyAnyMap_GetOrLoad(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			m     = gmap.NewAnyAnyMap(true)
+			wg    = sync.WaitGroup{}
+			calls = gtype.NewInt()
+		)
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				value, err := m.GetOrLoad(1, func(key interface{}) (interface{}, error) {
+					calls.Add(1)
+					time.Sleep(50 * time.Millisecond)
+					return key.(int) * 10, nil
+				})
+				t.AssertNil(err)
+				t.Assert(value, 10)
+			}()
+		}
+		wg.Wait()
+		t.Assert(calls.Val(), 1)
+		t.Assert(m.Get(1), 10)
+
+		value, err := m.GetOrLoad(1, func(key interface{}) (interface{}, error) {
+			return nil, gerror.New("should not be called")
+		})
+		t.AssertNil(err)
+		t.Assert(value, 10)
+	})
+	// Error is not cached.
+	gtest.C(t, func(t *gtest.T) {
+		var m gmap.AnyAnyMap
+		value, err := m.GetOrLoad(1, func(key interface{}) (interface{}, error) {
+			return nil, gerror.New("load failed")
+		})
+		t.Assert(err, "load failed")
+		t.Assert(value, nil)
+		t.Assert(m.Contains(1), false)
+
+		value, err = m.GetOrLoad(1, func(key interface{}) (interface{}, error) {
+			return "v", nil
+		})
+		t.AssertNil(err)
+		t.Assert(value, "v")
+		t.Assert(m.Get(1), "v")
+	})
+	// Panic in loader.
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewAnyAnyMap(true)
+		func() {
+			defer func() {
+				t.AssertNE(recover(), nil)
+			}()
+			m.GetOrLoad(1, func(key interface{}) (interface{}, error) {
+				panic("loader panic")
+			})
+		}()
+		t.Assert(m.Contains(1), false)
+		value, err := m.GetOrLoad(1, func(key interface{}) (interface{}, error) {
+			return 1, nil
+		})
+		t.AssertNil(err)
+		t.Assert(value, 1)
+	})
+}
+
+func Test_AnyAnyMap_Entries(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewAnyAnyMapFrom(g.MapAnyAny{1: 1, 2: nil, 3: "c"}, true)
+		values := m.ValuesNonNil()
+		t.Assert(len(values), 2)
+		t.AssertIN(1, values)
+		t.AssertIN("c", values)
+
+		entries := m.Entries()
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].Key.(int) < entries[j].Key.(int)
+		})
+		t.Assert(entries, []gmap.Entry{{1, 1}, {2, nil}, {3, "c"}})
+	})
+	gtest.C(t, func(t *gtest.T) {
+		var m gmap.AnyAnyMap
+		t.Assert(m.ValuesNonNil(), []interface{}{})
+		t.Assert(m.Entries(), []gmap.Entry{})
+	})
+}
+
+func Test_AnyAnyMap_OnSetOnRemove(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			m       = gmap.NewAnyAnyMap(true)
+			sets    = garray.New()
+			removes = garray.New()
+		)
+		m.OnSet(nil)
+		m.OnRemove(nil)
+		m.OnSet(func(key, oldValue, newValue interface{}) {
+			sets.Append(g.Slice{key, oldValue, newValue})
+		})
+		m.OnRemove(func(key, value interface{}) {
+			removes.Append(g.Slice{key, value})
+		})
+		m.Set(1, 1)
+		m.Set(1, 2)
+		m.GetOrSet(2, 2)
+		m.GetOrSet(2, 3)
+		m.SetIfNotExist(3, 3)
+		t.Assert(sets.Slice(), g.Slice{
+			g.Slice{1, nil, 1},
+			g.Slice{1, 1, 2},
+			g.Slice{2, nil, 2},
+			g.Slice{3, nil, 3},
+		})
+
+		t.Assert(m.Remove(1), 2)
+		t.Assert(m.Remove(1), nil)
+		m.Removes(g.Slice{2, 4})
+		t.Assert(removes.Slice(), g.Slice{
+			g.Slice{1, 2},
+			g.Slice{2, 2},
+		})
+
+		// Handlers are not called when replacing the data map as a whole.
+		sets.Clear()
+		removes.Clear()
+		m.Clear()
+		m.Replace(g.MapAnyAny{1: 1})
+		m.LockFunc(func(data map[interface{}]interface{}) {
+			data[2] = 2
+		})
+		t.Assert(sets.Len(), 0)
+		t.Assert(removes.Len(), 0)
+	})
+	// Multiple handlers.
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			m     gmap.AnyAnyMap
+			count = 0
+		)
+		m.OnSet(func(key, oldValue, newValue interface{}) { count++ })
+		m.OnSet(func(key, oldValue, newValue interface{}) { count += 10 })
+		m.Sets(g.MapAnyAny{1: 1, 2: 2})
+		t.Assert(count, 22)
+		t.Assert(m.Map(), g.MapAnyAny{1: 1, 2: 2})
+	})
+}
+
+func Test_AnyAnyMap_CompareAndSwap(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewAnyAnyMapFrom(g.MapAnyAny{1: 1, 2: g.Slice{1, 2}, 3: nil}, true)
+		t.Assert(m.CompareAndSwap(1, 2, 3), false)
+		t.Assert(m.CompareAndSwap(1, 1, 3), true)
+		t.Assert(m.Get(1), 3)
+		t.Assert(m.CompareAndSwap(2, g.Slice{1, 2}, "a"), true)
+		t.Assert(m.Get(2), "a")
+		t.Assert(m.CompareAndSwap(3, nil, 3), true)
+		t.Assert(m.Get(3), 3)
+		t.Assert(m.CompareAndSwap(4, nil, 4), false)
+		t.Assert(m.Contains(4), false)
+
+		t.Assert(m.CompareAndDelete(1, 1), false)
+		t.Assert(m.CompareAndDelete(1, 3), true)
+		t.Assert(m.Contains(1), false)
+		t.Assert(m.CompareAndDelete(1, nil), false)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			m       = gmap.NewAnyAnyMapFrom(g.MapAnyAny{1: 0}, true)
+			wg      = sync.WaitGroup{}
+			swapped = gtype.NewInt()
+		)
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				if m.CompareAndSwap(1, 0, i+1) {
+					swapped.Add(1)
+				}
+			}(i)
+		}
+		wg.Wait()
+		t.Assert(swapped.Val(), 1)
+	})
+}
+
+func Test_AnyAnyMap_IteratorE(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			m     = gmap.NewAnyAnyMapFrom(g.MapAnyAny{1: 1, 2: 2, 3: 3}, true)
+			count = 0
+		)
+		err := m.IteratorE(func(k interface{}, v interface{}) error {
+			count++
+			return nil
+		})
+		t.AssertNil(err)
+		t.Assert(count, 3)
+
+		count = 0
+		err = m.IteratorE(func(k interface{}, v interface{}) error {
+			count++
+			return gerror.Newf("failed at %v", k)
+		})
+		t.AssertNE(err, nil)
+		t.Assert(count, 1)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		var m gmap.AnyAnyMap
+		t.AssertNil(m.IteratorE(func(k interface{}, v interface{}) error {
+			return gerror.New("should not be called")
+		}))
+	})
+}
+
+func Test_AnyAnyMap_Equal(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m1 := gmap.NewAnyAnyMapFrom(g.MapAnyAny{1: 1, 2: g.Slice{1, 2}}, true)
+		m2 := gmap.NewAnyAnyMapFrom(g.MapAnyAny{1: 1, 2: g.Slice{1, 2}}, true)
+		t.Assert(m1.Equal(m2), true)
+		t.Assert(m2.Equal(m1), true)
+		t.Assert(m1.Equal(m1), true)
+		t.Assert(m1.Equal(nil), false)
+
+		m2.Set(2, g.Slice{2, 1})
+		t.Assert(m1.Equal(m2), false)
+		m2.Set(2, g.Slice{1, 2})
+		m2.Set(3, 3)
+		t.Assert(m1.Equal(m2), false)
+		m2.Remove(3)
+		m2.Remove(1)
+		m2.Set(4, 1)
+		t.Assert(m1.Equal(m2), false)
+
+		var empty1, empty2 gmap.AnyAnyMap
+		t.Assert(empty1.Equal(&empty2), true)
+		t.Assert(empty1.Equal(gmap.New()), true)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		m1 := gmap.NewAnyAnyMapFrom(g.MapAnyAny{1: 1, 2: "2"})
+		m2 := gmap.NewAnyAnyMapFrom(g.MapAnyAny{1: "1", 2: 2})
+		t.Assert(m1.Equal(m2), false)
+		t.Assert(m1.EqualFunc(m2, func(a, b interface{}) bool {
+			return gconv.String(a) == gconv.String(b)
+		}), true)
+	})
+	// Comparing with each other concurrently.
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			m1 = gmap.NewAnyAnyMapFrom(g.MapAnyAny{1: 1}, true)
+			m2 = gmap.NewAnyAnyMapFrom(g.MapAnyAny{1: 1}, true)
+			wg = sync.WaitGroup{}
+		)
+		for i := 0; i < 100; i++ {
+			wg.Add(3)
+			go func() {
+				defer wg.Done()
+				m1.Equal(m2)
+			}()
+			go func() {
+				defer wg.Done()
+				m2.Equal(m1)
+			}()
+			go func() {
+				defer wg.Done()
+				m1.Set(1, 1)
+				m2.Set(1, 1)
+			}()
+		}
+		wg.Wait()
+		t.Assert(m1.Equal(m2), true)
+	})
+	// The values of different kinds.
+	gtest.C(t, func(t *gtest.T) {
+		type myInt int
+		var (
+			one   = 1
+			other = 1
+			m     = gmap.NewAnyAnyMapFrom(g.MapAnyAny{
+				"int": 1, "int8": int8(1), "uint64": uint64(1), "float32": float32(1.5), "complex": complex(1, 2),
+				"string": "a", "bool": true, "nil": nil, "named": myInt(1), "pointer": &one,
+			})
+		)
+		t.Assert(m.Equal(m.Clone()), true)
+		for _, key := range []string{"int", "int8", "uint64", "named"} {
+			// The values of different types are not equal.
+			t.Assert(m.CompareAndSwap(key, int16(1), 2), false)
+		}
+		t.Assert(m.CompareAndSwap("float32", 1.5, 2), false)
+		t.Assert(m.CompareAndSwap("float32", float32(1.5), 2), true)
+		t.Assert(m.CompareAndSwap("complex", complex(1, 2), 2), true)
+		t.Assert(m.CompareAndSwap("nil", 0, 2), false)
+		t.Assert(m.CompareAndSwap("nil", nil, 2), true)
+		t.Assert(m.CompareAndSwap("named", myInt(1), 2), true)
+		// The pointers are equal if the pointed values are equal.
+		t.Assert(m.CompareAndDelete("pointer", &other), true)
+		t.Assert(m.CompareAndDelete("string", "b"), false)
+		t.Assert(m.CompareAndDelete("string", "a"), true)
+		t.Assert(m.CompareAndDelete("bool", false), false)
+		t.Assert(m.CompareAndDelete("bool", true), true)
+		t.Assert(m.Map(), g.MapAnyAny{
+			"int": 1, "int8": int8(1), "uint64": uint64(1), "float32": 2, "complex": 2, "nil": 2, "named": 2,
+		})
+	})
+}
+
+func Test_AnyAnyMap_SumValues(t *testing.T) {
+	type (
+		myInt    int
+		myString string
+	)
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewAnyAnyMapFrom(g.MapAnyAny{
+			1: 1,
+			2: "42",
+			3: 1.5,
+			4: uint8(2),
+			5: myInt(3),
+			6: myString("0.5"),
+			7: "abc",
+			8: nil,
+			9: g.Slice{1},
+		}, true)
+		t.Assert(m.SumValues(), 50)
+		t.Assert(m.AvgValues(), 50.0/6)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		var m gmap.AnyAnyMap
+		t.Assert(m.SumValues(), 0)
+		t.Assert(m.AvgValues(), 0)
+		m.Set(1, "a")
+		t.Assert(m.AvgValues(), 0)
+	})
+}
+
+func Test_AnyAnyMap_LoadOrStore(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		var m gmap.AnyAnyMap
+		actual, loaded := m.LoadOrStore(1, 1)
+		t.Assert(actual, 1)
+		t.Assert(loaded, false)
+		actual, loaded = m.LoadOrStore(1, 2)
+		t.Assert(actual, 1)
+		t.Assert(loaded, true)
+
+		actual, loaded = m.LoadOrStore(2, nil)
+		t.Assert(actual, nil)
+		t.Assert(loaded, false)
+		t.Assert(m.Contains(2), true)
+		actual, loaded = m.LoadOrStore(2, 2)
+		t.Assert(actual, nil)
+		t.Assert(loaded, true)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			m      = gmap.NewAnyAnyMap(true)
+			wg     = sync.WaitGroup{}
+			stored = gtype.NewInt()
+		)
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				if _, loaded := m.LoadOrStore(1, i); !loaded {
+					stored.Add(1)
+				}
+			}(i)
+		}
+		wg.Wait()
+		t.Assert(stored.Val(), 1)
+	})
+}
+
+func Test_AnyAnyMap_LockFuncCtx(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewAnyAnyMap(true)
+		err := m.LockFuncCtx(context.Background(), func(data map[interface{}]interface{}) {
+			data[1] = 1
+		})
+		t.AssertNil(err)
+		t.Assert(m.Get(1), 1)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err = m.LockFuncCtx(ctx, func(data map[interface{}]interface{}) {
+			data[2] = 2
+		})
+		t.Assert(err, context.Canceled)
+		t.Assert(m.Contains(2), false)
+	})
+	// Waiting for the lock.
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			m       = gmap.NewAnyAnyMap(true)
+			locked  = make(chan struct{})
+			release = make(chan struct{})
+		)
+		go m.LockFunc(func(data map[interface{}]interface{}) {
+			close(locked)
+			<-release
+		})
+		<-locked
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		called := false
+		err := m.LockFuncCtx(ctx, func(data map[interface{}]interface{}) {
+			called = true
+		})
+		t.Assert(err, context.DeadlineExceeded)
+		t.Assert(called, false)
+
+		time.AfterFunc(50*time.Millisecond, func() { close(release) })
+		err = m.LockFuncCtx(context.Background(), func(data map[interface{}]interface{}) {
+			data[1] = 1
+		})
+		t.AssertNil(err)
+		t.Assert(m.Get(1), 1)
+	})
+}
+
+func Test_AnyAnyMap_GetByPath(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m, err := gmap.NewFromJSON([]byte(`{
+			"name": "app",
+			"server": {
+				"listeners": [
+					{"port": 80},
+					{"port": 443, "tls": true}
+				]
+			}
+		}`), true)
+		t.AssertNil(err)
+		m.Set(1, g.MapAnyAny{"a": g.Slice{"b"}})
+
+		t.Assert(m.GetByPath("name"), "app")
+		t.Assert(m.GetByPath("server.listeners.0.port"), 80)
+		t.Assert(m.GetByPath("server.listeners.1.tls"), true)
+		t.Assert(len(m.GetByPath("server.listeners").([]interface{})), 2)
+		t.Assert(m.GetByPath("1"), nil)
+
+		t.Assert(m.GetByPath(""), nil)
+		t.Assert(m.GetByPath("none"), nil)
+		t.Assert(m.GetByPath("name.none"), nil)
+		t.Assert(m.GetByPath("server.listeners.2.port"), nil)
+		t.Assert(m.GetByPath("server.listeners.-1.port"), nil)
+		t.Assert(m.GetByPath("server.listeners.a"), nil)
+		t.Assert(m.GetByPath("server..listeners"), nil)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewFrom(g.MapAnyAny{"a": g.MapAnyAny{"b": g.Slice{g.Map{"c": 1}}}})
+		t.Assert(m.GetByPath("a.b.0.c"), 1)
+	})
+}
+
+func Test_AnyAnyMap_SetByPath(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		var m gmap.AnyAnyMap
+		t.AssertNil(m.SetByPath("name", "app"))
+		t.AssertNil(m.SetByPath("server.host", "127.0.0.1"))
+		t.AssertNil(m.SetByPath("server.tls.enabled", true))
+		t.AssertNil(m.SetByPath("server.listeners", g.Slice{g.Map{"port": 80}, nil}))
+		t.AssertNil(m.SetByPath("server.listeners.0.port", 8080))
+		t.AssertNil(m.SetByPath("server.listeners.1.port", 443))
+
+		t.Assert(m.GetByPath("name"), "app")
+		t.Assert(m.GetByPath("server.host"), "127.0.0.1")
+		t.Assert(m.GetByPath("server.tls.enabled"), true)
+		t.Assert(m.GetByPath("server.listeners.0.port"), 8080)
+		t.Assert(m.GetByPath("server.listeners.1.port"), 443)
+		t.Assert(m.GetByPath("server.tls"), g.Map{"enabled": true})
+
+		t.AssertNE(m.SetByPath("", 1), nil)
+		t.AssertNE(m.SetByPath("name.first", 1), nil)
+		t.AssertNE(m.SetByPath("server.host.ip", 1), nil)
+		t.AssertNE(m.SetByPath("server.listeners.2.port", 1), nil)
+		t.AssertNE(m.SetByPath("server.listeners.a", 1), nil)
+		t.Assert(m.GetByPath("name"), "app")
+	})
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewFrom(g.MapAnyAny{"a": g.MapAnyAny{"b": 1}}, true)
+		t.AssertNil(m.SetByPath("a.c.d", 2))
+		t.Assert(m.GetByPath("a.b"), 1)
+		t.Assert(m.GetByPath("a.c.d"), 2)
+	})
+	// The nested setting is the same as the Set of the top-level key.
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			nested         = g.Map{"b": 1, "c": g.Slice{1, 2}}
+			m              = gmap.NewFrom(g.MapAnyAny{"a": nested}, true)
+			sets           = garray.New(true)
+			events, cancel = m.Watch(10)
+		)
+		m.EnableStats(true)
+		m.OnSet(func(key, oldValue, newValue interface{}) {
+			sets.Append(g.Slice{key, oldValue, newValue})
+		})
+		clone := m.COWClone()
+		t.AssertNil(m.SetByPath("a.b", 2))
+		t.AssertNil(m.SetByPath("a.c.1", 3))
+		cancel()
+
+		t.Assert(m.Get("a"), g.Map{"b": 2, "c": g.Slice{1, 3}})
+		t.Assert(m.Stats().Sets, 2)
+		t.Assert(sets.Slice(), g.Slice{
+			g.Slice{"a", g.Map{"b": 1, "c": g.Slice{1, 2}}, g.Map{"b": 2, "c": g.Slice{1, 2}}},
+			g.Slice{"a", g.Map{"b": 2, "c": g.Slice{1, 2}}, g.Map{"b": 2, "c": g.Slice{1, 3}}},
+		})
+		var received []gmap.Event
+		for event := range events {
+			received = append(received, event)
+		}
+		t.Assert(len(received), 2)
+		t.Assert(received[1].Key, "a")
+		t.Assert(received[1].New, g.Map{"b": 2, "c": g.Slice{1, 3}})
+
+		// The nested values shared with the clone and the original value are unchanged.
+		t.Assert(nested, g.Map{"b": 1, "c": g.Slice{1, 2}})
+		t.Assert(clone.Get("a"), g.Map{"b": 1, "c": g.Slice{1, 2}})
+	})
+	// The map is unchanged if the path is invalid.
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewFrom(g.MapAnyAny{"a": g.Map{"b": g.Slice{}}}, true)
+		m.EnableStats(true)
+		t.AssertNE(m.SetByPath("a.b.0.c", 1), nil)
+		t.Assert(m.Map(), g.MapAnyAny{"a": g.Map{"b": g.Slice{}}})
+		t.Assert(m.Stats().Sets, 0)
+	})
+}
+
+func Test_AnyAnyMap_Freeze(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewFrom(g.MapAnyAny{"a": 1, "b": 2}, true)
+		t.Assert(m.IsFrozen(), false)
+		m.Freeze()
+		t.Assert(m.IsFrozen(), true)
+
+		mustPanic := func(f func()) {
+			defer func() {
+				err, ok := recover().(error)
+				t.Assert(ok, true)
+				t.Assert(gerror.Code(err), gcode.CodeInvalidOperation)
+			}()
+			f()
+		}
+		mustPanic(func() { m.Set("c", 3) })
+		mustPanic(func() { m.Sets(g.MapAnyAny{"c": 3}) })
+		mustPanic(func() { m.Remove("a") })
+		mustPanic(func() { m.Removes([]interface{}{"a"}) })
+		mustPanic(func() { m.Clear() })
+		mustPanic(func() { m.Flip() })
+		mustPanic(func() { m.Merge(gmap.New()) })
+		mustPanic(func() { m.GetOrSet("c", 3) })
+		mustPanic(func() { m.SetIfNotExist("c", 3) })
+		mustPanic(func() { m.LoadOrStore("c", 3) })
+		mustPanic(func() {
+			_, _ = m.GetOrLoad("c", func(key interface{}) (interface{}, error) {
+				return 3, nil
+			})
+		})
+		mustPanic(func() { m.LockFunc(func(m map[interface{}]interface{}) {}) })
+
+		// Reading methods work normally, including the ones not changing the existing keys.
+		t.Assert(m.Map(), g.MapAnyAny{"a": 1, "b": 2})
+		t.Assert(m.GetOrSet("a", 10), 1)
+		actual, loaded := m.LoadOrStore("b", 20)
+		t.Assert(actual, 2)
+		t.Assert(loaded, true)
+
+		// The lock is not held after panics.
+		t.Assert(m.Size(), 2)
+		c := m.Clone()
+		t.Assert(c.IsFrozen(), false)
+		c.Set("c", 3)
+		t.Assert(c.Size(), 3)
+	})
+}
+
+func Test_AnyAnyMap_COWClone(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewFrom(g.MapAnyAny{"a": 1, "b": 2}, true)
+		c := m.COWClone()
+		t.Assert(c.Map(), g.MapAnyAny{"a": 1, "b": 2})
+
+		c.Set("c", 3)
+		t.Assert(m.Map(), g.MapAnyAny{"a": 1, "b": 2})
+		t.Assert(c.Map(), g.MapAnyAny{"a": 1, "b": 2, "c": 3})
+
+		m.Remove("a")
+		t.Assert(m.Map(), g.MapAnyAny{"b": 2})
+		t.Assert(c.Map(), g.MapAnyAny{"a": 1, "b": 2, "c": 3})
+
+		// Removing absent key does not copy.
+		c2 := m.COWClone(true)
+		c2.Remove("z")
+		m.LockFunc(func(data map[interface{}]interface{}) {
+			data["d"] = 4
+		})
+		t.Assert(m.Map(), g.MapAnyAny{"b": 2, "d": 4})
+		t.Assert(c2.Map(), g.MapAnyAny{"b": 2})
+
+		c3 := m.COWClone()
+		m.Clear()
+		t.Assert(c3.Map(), g.MapAnyAny{"b": 2, "d": 4})
+		c3.Pops(-1)
+		t.Assert(c3.Size(), 0)
+		t.Assert(c2.Size(), 1)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		var m gmap.AnyAnyMap
+		c := m.COWClone()
+		c.Set(1, 1)
+		t.Assert(m.Size(), 0)
+		t.Assert(c.Size(), 1)
+	})
+	// Concurrent changes on both sides.
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.New(true)
+		for i := 0; i < 100; i++ {
+			m.Set(i, i)
+		}
+		var (
+			wg     sync.WaitGroup
+			clones = make([]*gmap.Map, 10)
+		)
+		for i := range clones {
+			clones[i] = m.COWClone(true)
+		}
+		for i, c := range clones {
+			wg.Add(1)
+			go func(i int, c *gmap.Map) {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					c.Set(j, i)
+					m.Get(j)
+				}
+			}(i, c)
+		}
+		wg.Wait()
+		for i, c := range clones {
+			t.Assert(c.Get(99), i)
+		}
+		t.Assert(m.Get(99), 99)
+	})
+}
+
+func Test_AnyAnyMap_Chunk_Page(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewFrom(g.MapAnyAny{"e": 5, "a": 1, "d": 4, "c": 3, "b": 2}, true)
+		t.Assert(m.Chunk(0), nil)
+		t.Assert(m.Chunk(2), [][]interface{}{{"a", "b"}, {"c", "d"}, {"e"}})
+		t.Assert(m.Chunk(5), [][]interface{}{{"a", "b", "c", "d", "e"}})
+
+		t.Assert(m.Page(0, 2), []gmap.Entry{{Key: "a", Value: 1}, {Key: "b", Value: 2}})
+		t.Assert(m.Page(4, 2), []gmap.Entry{{Key: "e", Value: 5}})
+		t.Assert(m.Page(-1, 1), []gmap.Entry{{Key: "a", Value: 1}})
+		t.Assert(m.Page(5, 2), nil)
+		t.Assert(m.Page(0, 0), nil)
+
+		// Pages are repeatable and cover all entries.
+		var keys []interface{}
+		for offset := 0; offset < m.Size(); offset += 2 {
+			for _, e := range m.Page(offset, 2) {
+				keys = append(keys, e.Key)
+			}
+		}
+		t.Assert(keys, []interface{}{"a", "b", "c", "d", "e"})
+	})
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewFrom(g.MapAnyAny{10: "a", 9: "b", 100: "c"})
+		t.Assert(m.Page(0, 3), []gmap.Entry{{Key: 10, Value: "a"}, {Key: 100, Value: "c"}, {Key: 9, Value: "b"}})
+		t.Assert(m.Page(0, 3, func(a, b interface{}) int {
+			return a.(int) - b.(int)
+		}), []gmap.Entry{{Key: 9, Value: "b"}, {Key: 10, Value: "a"}, {Key: 100, Value: "c"}})
+		t.Assert(gmap.New().Chunk(1), [][]interface{}{})
+	})
+}
+
+func Test_AnyAnyMap_Increment(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewFrom(g.MapAnyAny{
+			"int":    1,
+			"uint8":  uint8(250),
+			"float":  float32(1.5),
+			"string": "10",
+			"other":  "a",
+		}, true)
+		t.Assert(m.Increment("int", 2), 3)
+		t.AssertEQ(m.Get("int"), int(3))
+		t.Assert(m.Decrement("int", 5), -2)
+		t.AssertEQ(m.Get("int"), int(-2))
+		t.Assert(m.Increment("int", 0.5), -1.5)
+		t.AssertEQ(m.Get("int"), float64(-1.5))
+
+		t.Assert(m.Increment("uint8", 5), 255)
+		t.AssertEQ(m.Get("uint8"), uint8(255))
+		t.Assert(m.Increment("uint8", 1), 256)
+		t.AssertEQ(m.Get("uint8"), float64(256))
+
+		t.Assert(m.Increment("float", 1), 2.5)
+		t.AssertEQ(m.Get("float"), float32(2.5))
+
+		t.Assert(m.Increment("string", 1), 11)
+		t.AssertEQ(m.Get("string"), float64(11))
+		t.Assert(m.Increment("other", 1), 1)
+		t.Assert(m.Increment("missing", 1), 1)
+		t.AssertEQ(m.Get("missing"), float64(1))
+	})
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			m  = gmap.NewFrom(g.MapAnyAny{"count": 0}, true)
+			wg sync.WaitGroup
+		)
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				m.Increment("count", 1)
+			}()
+		}
+		wg.Wait()
+		t.Assert(m.Get("count"), 100)
+	})
+}
+
+func Test_AnyAnyMap_Gob(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewFrom(g.MapAnyAny{1: "a", "b": 2, "c": []string{"x", "y"}, "d": nil}, true)
+		var buffer bytes.Buffer
+		t.AssertNil(gob.NewEncoder(&buffer).Encode(m))
+
+		n := gmap.New(true)
+		n.Set("z", 26)
+		t.AssertNil(gob.NewDecoder(&buffer).Decode(n))
+		t.Assert(n.Map(), g.MapAnyAny{1: "a", "b": 2, "c": []string{"x", "y"}, "d": nil})
+		t.AssertEQ(n.Get(1), "a")
+		t.AssertEQ(n.Get("b"), 2)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		type Cache struct {
+			Name string
+			Data *gmap.Map
+		}
+		var (
+			buffer bytes.Buffer
+			cache  = Cache{Name: "cache", Data: gmap.NewFrom(g.MapAnyAny{"k": "v"})}
+			result Cache
+		)
+		t.AssertNil(gob.NewEncoder(&buffer).Encode(cache))
+		t.AssertNil(gob.NewDecoder(&buffer).Decode(&result))
+		t.Assert(result.Name, "cache")
+		t.Assert(result.Data.Map(), g.MapAnyAny{"k": "v"})
+
+		// Unregistered types cannot be encoded.
+		type unregistered struct{ A int }
+		_, err := gmap.NewFrom(g.MapAnyAny{"k": unregistered{1}}).GobEncode()
+		t.AssertNE(err, nil)
+		t.AssertNE(gmap.New().GobDecode([]byte("invalid")), nil)
+	})
+}
+
+func Test_AnyAnyMap_LockFuncR(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewFrom(g.MapAnyAny{"a": 1, "b": 2}, true)
+		total := m.RLockFuncR(func(data map[interface{}]interface{}) interface{} {
+			sum := 0
+			for _, v := range data {
+				sum += v.(int)
+			}
+			return sum
+		})
+		t.Assert(total, 3)
+
+		old := m.LockFuncR(func(data map[interface{}]interface{}) interface{} {
+			old := data["a"]
+			data["a"] = 10
+			return old
+		})
+		t.Assert(old, 1)
+		t.Assert(m.Get("a"), 10)
+
+		var empty gmap.Map
+		t.Assert(empty.RLockFuncR(func(data map[interface{}]interface{}) interface{} {
+			return len(data)
+		}), 0)
+	})
+}
+
+func Test_AnyAnyMap_ToTypedMap(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewFrom(g.MapAnyAny{1: "a", "b": 2}, true)
+		s := m.ToStrAnyMap()
+		t.Assert(s.Map(), g.MapStrAny{"1": "a", "b": 2})
+		s.Set("c", 3)
+		t.Assert(m.Size(), 2)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewFrom(g.MapAnyAny{1: 10, int64(2): "20", "3": 30.0, uint8(4): int8(-40)})
+		i, err := m.ToIntIntMapE()
+		t.AssertNil(err)
+		t.Assert(i.Map(), map[int]int{1: 10, 2: 20, 3: 30, 4: -40})
+
+		_, err = gmap.NewFrom(g.MapAnyAny{"a": 1}).ToIntIntMapE()
+		t.AssertNE(err, nil)
+		_, err = gmap.NewFrom(g.MapAnyAny{1: 1.5}).ToIntIntMapE()
+		t.AssertNE(err, nil)
+		_, err = gmap.NewFrom(g.MapAnyAny{uint64(1 << 63): 1}).ToIntIntMapE()
+		t.AssertNE(err, nil)
+	})
+}
+
+func Test_AnyAnyMap_RekeyFunc(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewFrom(g.MapAnyAny{"user_1": "john", "user_2": "smith", "tmp": 1}, true)
+		m.RekeyFunc(func(oldKey, value interface{}) (newKey interface{}, keep bool) {
+			key := oldKey.(string)
+			if !strings.HasPrefix(key, "user_") {
+				return nil, false
+			}
+			return gconv.Int(strings.TrimPrefix(key, "user_")), true
+		})
+		t.Assert(m.Map(), g.MapAnyAny{1: "john", 2: "smith"})
+
+		// Collision keeps one of the values.
+		m.RekeyFunc(func(oldKey, value interface{}) (newKey interface{}, keep bool) {
+			return "user", true
+		})
+		t.Assert(m.Size(), 1)
+		t.AssertIN(m.Get("user"), g.Slice{"john", "smith"})
+	})
+}
+
+func Test_AnyAnyMap_Watch(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.New(true)
+		events, cancel := m.Watch(10)
+		m.Set("a", 1)
+		m.Set("a", 2)
+		m.Remove("a")
+		m.Remove("z")
+		m.Sets(g.MapAnyAny{"b": 3})
+		m.Clear()
+		cancel()
+		cancel()
+		m.Set("c", 4)
+
+		var received []gmap.Event
+		for event := range events {
+			received = append(received, event)
+		}
+		t.Assert(received, []gmap.Event{
+			{Type: gmap.EventSet, Key: "a", New: 1},
+			{Type: gmap.EventSet, Key: "a", Old: 1, New: 2},
+			{Type: gmap.EventRemove, Key: "a", Old: 2},
+			{Type: gmap.EventSet, Key: "b", New: 3},
+			{Type: gmap.EventClear},
+		})
+		t.Assert(m.WatchDropped(), 0)
+	})
+	// Events are dropped if the buffer is full.
+	gtest.C(t, func(t *gtest.T) {
+		var m gmap.Map
+		events1, cancel1 := m.Watch(1)
+		events2, cancel2 := m.Watch(3)
+		defer cancel1()
+		defer cancel2()
+		m.Set(1, 1)
+		m.Set(2, 2)
+		m.Set(3, 3)
+		t.Assert(m.WatchDropped(), 2)
+		t.Assert((<-events1).Key, 1)
+		t.Assert(len(events2), 3)
+	})
+}
+
+func Test_AnyAnyMap_Stats(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.New(true)
+		m.EnableStats(true)
+		m.Set("a", 1)
+		m.Sets(g.MapAnyAny{"b": 2, "c": 3})
+		m.Get("a")
+		m.Search("z")
+		m.Gets([]interface{}{"a", "b"})
+		m.GetOrSet("a", 10)
+		m.GetOrSetFunc("d", func() interface{} { return 4 })
+		m.LoadOrStore("e", 5)
+		m.LoadOrStore("e", 50)
+		m.Remove("a")
+		m.Remove("z")
+		t.Assert(m.Stats(), gmap.MapStats{
+			Size:    4,
+			Sets:    5,
+			Gets:    6,
+			Hits:    2,
+			Misses:  2,
+			Removes: 1,
+		})
+
+		m.ResetStats()
+		t.Assert(m.Stats(), gmap.MapStats{Size: 4})
+
+		// The usage is not counted if the statistics are disabled.
+		m.Get("a")
+		m.EnableStats(false)
+		m.Get("a")
+		m.Set("f", 6)
+		m.ResetStats()
+		t.Assert(m.Stats(), gmap.MapStats{Size: 5})
+		m.EnableStats(true)
+		m.EnableStats(true)
+		m.Get("a")
+		t.Assert(m.Stats(), gmap.MapStats{Size: 5, Gets: 1})
+	})
+	gtest.C(t, func(t *gtest.T) {
+		var m gmap.Map
+		m.Set("a", 1)
+		m.Get("a")
+		m.GetOrSet("b", 2)
+		t.Assert(m.Stats(), gmap.MapStats{Size: 2})
+	})
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			m  = gmap.New(true)
+			wg sync.WaitGroup
+		)
+		m.EnableStats(true)
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				m.GetOrSetFuncLock(1, func() interface{} { return 1 })
+			}()
+		}
+		wg.Wait()
+		stats := m.Stats()
+		t.Assert(stats.Hits, 99)
+		t.Assert(stats.Misses, 1)
+		t.Assert(stats.Sets, 1)
+	})
+}
+
+func Test_AnyAnyMap_SortedKeys(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		t.Assert(gmap.New().SortedKeys(), g.Slice{})
+		t.Assert(gmap.NewFrom(g.MapAnyAny{10: 1, 9: 1, -100: 1, int64(2): 1}).SortedKeys(), g.Slice{-100, 2, 9, 10})
+		t.Assert(gmap.NewFrom(g.MapAnyAny{uint(10): 1, uint8(9): 1}).SortedKeys(), g.Slice{9, 10})
+		t.Assert(gmap.NewFrom(g.MapAnyAny{1.5: 1, -0.5: 1, float32(10): 1}).SortedKeys(), g.Slice{-0.5, 1.5, 10})
+		t.Assert(gmap.NewFrom(g.MapAnyAny{"b": 1, "a": 1, "B": 1}).SortedKeys(), g.Slice{"B", "a", "b"})
+		// Mixed kinds are sorted by fmt.Sprint.
+		t.Assert(gmap.NewFrom(g.MapAnyAny{10: 1, 9: 1, "a": 1}).SortedKeys(), g.Slice{10, 9, "a"})
+	})
+}
+
+func Test_AnyAnyMap_MergeReport(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewFrom(g.MapAnyAny{"a": 1, "b": 2, "c": g.Slice{1}}, true)
+		other := gmap.NewFrom(g.MapAnyAny{"b": 20, "c": g.Slice{1}, "d": 4}, true)
+		t.Assert(m.MergeReport(other), g.Slice{"b"})
+		t.Assert(m.Map(), g.MapAnyAny{"a": 1, "b": 20, "c": g.Slice{1}, "d": 4})
+		t.Assert(m.MergeReport(m), nil)
+		t.Assert(m.MergeReport(nil), nil)
+
+		var empty gmap.Map
+		t.Assert(empty.MergeReport(other), nil)
+		t.Assert(empty.Size(), 3)
+	})
+	// Merging into each other concurrently does not deadlock.
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			m1 = gmap.NewFrom(g.MapAnyAny{1: 1}, true)
+			m2 = gmap.NewFrom(g.MapAnyAny{1: 2}, true)
+			wg sync.WaitGroup
+		)
+		for i := 0; i < 100; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				m1.MergeReport(m2)
+			}()
+			go func() {
+				defer wg.Done()
+				m2.MergeReport(m1)
+			}()
+		}
+		wg.Wait()
+		t.Assert(m1.Map(), m2.Map())
+	})
+}
+
+func Test_AnyAnyMap_Sample(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewAnyAnyMap(true)
+		t.Assert(len(m.Sample(3)), 0)
+		for i := 0; i < 10; i++ {
+			m.Set(i, i*10)
+		}
+		t.Assert(m.Sample(0), nil)
+		t.Assert(len(m.Sample(20)), 10)
+
+		counts := make(map[interface{}]int)
+		for i := 0; i < 1000; i++ {
+			entries := m.Sample(3)
+			t.Assert(len(entries), 3)
+			seen := make(map[interface{}]bool)
+			for _, entry := range entries {
+				t.Assert(entry.Value, entry.Key.(int)*10)
+				t.Assert(seen[entry.Key], false)
+				seen[entry.Key] = true
+				counts[entry.Key]++
+			}
+		}
+		// Each key is expected to be chosen 300 times.
+		t.Assert(len(counts), 10)
+		for _, count := range counts {
+			t.Assert(count > 150 && count < 450, true)
+		}
+	})
+}
+
+func Test_AnyAnyMap_WeightedSample(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewAnyAnyMapFrom(g.MapAnyAny{"a": 1, "b": 0, "c": -1, "d": 9})
+		weight := func(k, v interface{}) float64 {
+			return float64(v.(int))
+		}
+		t.Assert(m.WeightedSample(0, weight), nil)
+		entries := m.WeightedSample(10, weight)
+		t.Assert(len(entries), 2)
+
+		counts := make(map[interface{}]int)
+		for i := 0; i < 1000; i++ {
+			entries = m.WeightedSample(1, weight)
+			t.Assert(len(entries), 1)
+			counts[entries[0].Key]++
+		}
+		// The key "d" is expected to be chosen 900 times.
+		t.Assert(counts["a"]+counts["d"], 1000)
+		t.Assert(counts["d"] > 800, true)
+	})
+}
+
+func Test_AnyAnyMap_Scan(t *testing.T) {
+	type Base struct {
+		Id int64 `json:"id"`
+	}
+	type Config struct {
+		Base
+		Name    string  `gmap:"name" json:"title"`
+		Port    uint16  `json:"port,omitempty"`
+		Ratio   float32 `gmap:"ratio"`
+		Debug   bool
+		Timeout *int
+		Ignored string `gmap:"-"`
+		Missing string
+		private string
+	}
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewAnyAnyMapFrom(g.MapAnyAny{
+			"id":      "100",
+			"name":    "john",
+			"title":   "ignored",
+			"port":    8080.0,
+			"ratio":   " 0.5 ",
+			"Debug":   "true",
+			"Timeout": 30,
+			"Ignored": "ignored",
+			"private": "ignored",
+		})
+		config := Config{Missing: "default"}
+		t.AssertNil(m.Scan(&config))
+		t.Assert(config.Id, 100)
+		t.Assert(config.Name, "john")
+		t.Assert(config.Port, 8080)
+		t.Assert(config.Ratio, 0.5)
+		t.Assert(config.Debug, true)
+		t.Assert(*config.Timeout, 30)
+		t.Assert(config.Ignored, "")
+		t.Assert(config.Missing, "default")
+		t.Assert(config.private, "")
+	})
+	gtest.C(t, func(t *gtest.T) {
+		var config Config
+		t.AssertNE(gmap.New().Scan(config), nil)
+		t.AssertNE(gmap.New().Scan(nil), nil)
+		t.AssertNE(gmap.New().Scan(new(int)), nil)
+
+		// The bool values are converted to numbers.
+		t.AssertNil(gmap.NewFrom(g.MapAnyAny{"id": false, "port": true, "ratio": true}).Scan(&config))
+		t.Assert(config.Id, 0)
+		t.Assert(config.Port, 1)
+		t.Assert(config.Ratio, 1)
+
+		t.AssertNE(gmap.NewFrom(g.MapAnyAny{"port": "http"}).Scan(&config), nil)
+		t.AssertNE(gmap.NewFrom(g.MapAnyAny{"port": 70000}).Scan(&config), nil)
+		t.AssertNE(gmap.NewFrom(g.MapAnyAny{"port": -1}).Scan(&config), nil)
+		t.AssertNE(gmap.NewFrom(g.MapAnyAny{"port": 1.5}).Scan(&config), nil)
+		t.AssertNE(gmap.NewFrom(g.MapAnyAny{"Debug": 1}).Scan(&config), nil)
+		t.AssertNE(gmap.NewFrom(g.MapAnyAny{"name": []int{1}}).Scan(&config), nil)
+		err := gmap.NewFrom(g.MapAnyAny{"id": "abc"}).Scan(&config)
+		t.Assert(gerror.Code(err), gcode.CodeInvalidParameter)
+		t.Assert(strings.Contains(err.Error(), `"id"`), true)
+	})
+}
+
+func Test_AnyAnyMap_Move(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewAnyAnyMapFrom(g.MapAnyAny{1: "a", 2: "b"}, true)
+		t.Assert(m.Move(3, 4), false)
+		t.Assert(m.Map(), g.MapAnyAny{1: "a", 2: "b"})
+
+		t.Assert(m.Move(1, 3), true)
+		t.Assert(m.Map(), g.MapAnyAny{2: "b", 3: "a"})
+		t.Assert(m.Move(2, 3), true)
+		t.Assert(m.Map(), g.MapAnyAny{3: "b"})
+		t.Assert(m.Move(3, 3), true)
+		t.Assert(m.Map(), g.MapAnyAny{3: "b"})
+	})
+	// Concurrent moving never loses the value.
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			m  = gmap.NewAnyAnyMapFrom(g.MapAnyAny{0: "v"}, true)
+			wg sync.WaitGroup
+		)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					m.Move(j%10, (j+i)%10)
+				}
+			}(i)
+		}
+		wg.Wait()
+		t.Assert(m.Size(), 1)
+		t.Assert(m.Values(), g.Slice{"v"})
+	})
+}
+
+func Test_AnyAnyMap_SizeInBytes(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewAnyAnyMap(true)
+		t.Assert(m.SizeInBytes(), 0)
+
+		m.Set(1, "")
+		base := m.SizeInBytes()
+		t.Assert(base > 0, true)
+		m.Set(1, "0123456789")
+		t.Assert(m.SizeInBytes(), base+10)
+
+		m.Set(1, make([]byte, 5, 1000))
+		t.Assert(m.SizeInBytes() > base+1000, true)
+		m.Set(1, g.Map{"key": strings.Repeat("a", 1000)})
+		t.Assert(m.SizeInBytes() > base+1000, true)
+	})
+	// Cyclic references.
+	gtest.C(t, func(t *gtest.T) {
+		type Node struct {
+			Name string
+			Next *Node
+		}
+		var (
+			node = &Node{Name: "node"}
+			data = g.Map{}
+		)
+		var (
+			slice  = make(g.Slice, 2)
+			nested = g.Slice{g.Map{}}
+		)
+		node.Next = node
+		data["self"] = data
+		slice[0] = slice
+		nested[0].(g.Map)["slice"] = nested
+		m := gmap.NewAnyAnyMapFrom(g.MapAnyAny{"node": node, "data": data, "slice": slice, "nested": nested})
+		t.Assert(m.SizeInBytes() > 0, true)
+	})
+}
+
+func Test_AnyAnyMap_GetOrSetFunc_Concurrent(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			m       = gmap.NewAnyAnyMap(true)
+			calls   = gtype.NewInt()
+			results = make(chan interface{}, 100)
+			wg      sync.WaitGroup
+		)
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results <- m.GetOrSetFunc("key", func() interface{} {
+					time.Sleep(10 * time.Millisecond)
+					return calls.Add(1)
+				})
+			}()
+		}
+		wg.Wait()
+		close(results)
+		t.Assert(calls.Val(), 1)
+		for result := range results {
+			t.Assert(result, 1)
+		}
+		t.Assert(m.Get("key"), 1)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewAnyAnyMap(true)
+		t.Assert(m.GetOrSetFunc("key", func() interface{} { return nil }), nil)
+		t.Assert(m.Contains("key"), false)
+		// The panic of `f` is propagated, and the key can be set again.
+		func() {
+			defer func() {
+				t.AssertNE(recover(), nil)
+			}()
+			m.GetOrSetFunc("key", func() interface{} { panic("error") })
+		}()
+		t.Assert(m.GetOrSetFunc("key", func() interface{} { return 1 }), 1)
+	})
+	// The value set while running `f` is kept.
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewAnyAnyMap(true)
+		t.Assert(m.GetOrSetFunc("key", func() interface{} {
+			m.Set("key", "explicit")
+			return "loader"
+		}), "explicit")
+		t.Assert(m.Get("key"), "explicit")
+
+		value, err := m.GetOrLoad("load", func(key interface{}) (interface{}, error) {
+			m.Set("load", "explicit")
+			return "loader", nil
+		})
+		t.AssertNil(err)
+		t.Assert(value, "explicit")
+		t.Assert(m.Get("load"), "explicit")
+
+		// The waiting callers get the kept value too.
+		var (
+			wg      sync.WaitGroup
+			started = make(chan struct{})
+			release = make(chan struct{})
+			waited  interface{}
+		)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.GetOrSetFunc("wait", func() interface{} {
+				close(started)
+				<-release
+				return "loader"
+			})
+		}()
+		<-started
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			waited = m.GetOrSetFunc("wait", func() interface{} { return "other" })
+		}()
+		time.Sleep(50 * time.Millisecond)
+		m.Set("wait", "explicit")
+		close(release)
+		wg.Wait()
+		t.Assert(waited, "explicit")
+		t.Assert(m.Get("wait"), "explicit")
+	})
+}
+
+func Test_AnyAnyMap_TypedGet(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewAnyAnyMapFrom(g.MapAnyAny{
+			"int":    100,
+			"int8":   int8(-8),
+			"string": "42",
+			"float":  1.5,
+			"bool":   true,
+			"true":   "true",
+			"bytes":  []byte("abc"),
+			"text":   "abc",
+			"nil":    nil,
+		}, true)
+		i, ok := m.GetInt("int")
+		t.Assert(i, 100)
+		t.Assert(ok, true)
+		i, ok = m.GetInt("int8")
+		t.Assert(i, -8)
+		t.Assert(ok, true)
+		i, ok = m.GetInt("string")
+		t.Assert(i, 42)
+		t.Assert(ok, true)
+		i, ok = m.GetInt("bool")
+		t.Assert(i, 1)
+		t.Assert(ok, true)
+		for _, key := range []string{"float", "text", "nil", "missing"} {
+			i, ok = m.GetInt(key)
+			t.Assert(i, 0)
+			t.Assert(ok, false)
+		}
+
+		s, ok := m.GetString("int")
+		t.Assert(s, "100")
+		t.Assert(ok, true)
+		s, ok = m.GetString("bytes")
+		t.Assert(s, "abc")
+		t.Assert(ok, true)
+		s, ok = m.GetString("bool")
+		t.Assert(s, "true")
+		t.Assert(ok, true)
+		_, ok = m.GetString("missing")
+		t.Assert(ok, false)
+
+		b, ok := m.GetBool("bool")
+		t.Assert(b, true)
+		t.Assert(ok, true)
+		b, ok = m.GetBool("true")
+		t.Assert(b, true)
+		t.Assert(ok, true)
+		b, ok = m.GetBool("text")
+		t.Assert(b, false)
+		t.Assert(ok, false)
+		_, ok = m.GetBool("int")
+		t.Assert(ok, false)
+
+		f, ok := m.GetFloat64("float")
+		t.Assert(f, 1.5)
+		t.Assert(ok, true)
+		f, ok = m.GetFloat64("string")
+		t.Assert(f, 42)
+		t.Assert(ok, true)
+		f, ok = m.GetFloat64("bool")
+		t.Assert(f, 1)
+		t.Assert(ok, true)
+		f, ok = m.GetFloat64("text")
+		t.Assert(f, 0)
+		t.Assert(ok, false)
+		_, ok = m.GetFloat64("nil")
+		t.Assert(ok, false)
+	})
+}
+
+func Test_AnyAnyMap_SetSafe(t *testing.T) {
+	type Key struct {
+		Name  string
+		Value interface{}
+	}
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewAnyAnyMap(true)
+		t.AssertNil(m.SetSafe(1, 1))
+		t.AssertNil(m.SetSafe(nil, 2))
+		t.AssertNil(m.SetSafe(Key{Name: "a", Value: 1}, 3))
+		t.AssertNil(m.SetSafe([2]interface{}{1, "a"}, 4))
+		t.AssertNil(m.SetSafe(&Key{}, 5))
+		t.Assert(m.Size(), 5)
+		t.Assert(m.Get(Key{Name: "a", Value: 1}), 3)
+
+		for _, key := range []interface{}{
+			[]int{1},
+			map[string]int{},
+			func() {},
+			Key{Name: "a", Value: []int{1}},
+			[1]interface{}{map[int]int{}},
+			[1][]int{},
+		} {
+			err := m.SetSafe(key, 1)
+			t.AssertNE(err, nil)
+			t.Assert(gerror.Code(err), gcode.CodeInvalidParameter)
+		}
+		t.Assert(m.Size(), 5)
+		// The map keeps working after the failures.
+		m.Set(2, 2)
+		t.Assert(m.Size(), 6)
+	})
+}
+
+func Test_AnyAnyMap_KeysMatch_RemoveMatch(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewAnyAnyMapFrom(g.MapAnyAny{
+			"session:a1":  1,
+			"session:b2":  2,
+			"user:1:name": 3,
+			"user:2:name": 4,
+			"user:2:age":  5,
+			100:           6,
+		}, true)
+		sortKeys := func(keys []interface{}) []string {
+			result := make([]string, len(keys))
+			for i, key := range keys {
+				result[i] = gconv.String(key)
+			}
+			sort.Strings(result)
+			return result
+		}
+		t.Assert(sortKeys(m.KeysMatch("session:*")), []string{"session:a1", "session:b2"})
+		t.Assert(sortKeys(m.KeysMatch("user:*:name")), []string{"user:1:name", "user:2:name"})
+		t.Assert(sortKeys(m.KeysMatch("*:?1")), []string{"session:a1"})
+		t.Assert(sortKeys(m.KeysMatch("1[0-9]0")), []string{"100"})
+		t.Assert(m.KeysMatch("none*"), nil)
+		t.Assert(m.KeysMatch("["), nil)
+
+		t.Assert(m.RemoveMatch("["), 0)
+		t.Assert(m.RemoveMatch("user:2:*"), 2)
+		t.Assert(m.RemoveMatch("session:*"), 2)
+		t.Assert(m.RemoveMatch("session:*"), 0)
+		t.Assert(m.Map(), g.MapAnyAny{"user:1:name": 3, 100: 6})
+	})
+}
+
+func Test_AnyAnyMap_SetIfNotExistGet(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewAnyAnyMap(true)
+		stored, created := m.SetIfNotExistGet(1, "a")
+		t.Assert(stored, "a")
+		t.Assert(created, true)
+		stored, created = m.SetIfNotExistGet(1, "b")
+		t.Assert(stored, "a")
+		t.Assert(created, false)
+		stored, created = m.SetIfNotExistGet(2, func() interface{} { return "c" })
+		t.Assert(stored, "c")
+		t.Assert(created, true)
+		// The nil value is not stored.
+		stored, created = m.SetIfNotExistGet(3, nil)
+		t.Assert(stored, nil)
+		t.Assert(created, false)
+		stored, created = m.SetIfNotExistGet(4, func() interface{} { return nil })
+		t.Assert(stored, nil)
+		t.Assert(created, false)
+		t.Assert(m.Map(), g.MapAnyAny{1: "a", 2: "c"})
+	})
+	// Only one of the concurrent callers creates the value.
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			m       = gmap.NewAnyAnyMap(true)
+			created = gtype.NewInt()
+			wg      sync.WaitGroup
+		)
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				stored, ok := m.SetIfNotExistGet("key", i)
+				if ok {
+					created.Add(1)
+				}
+				t.Assert(stored, m.Get("key"))
+			}(i)
+		}
+		wg.Wait()
+		t.Assert(created.Val(), 1)
+	})
+}
+
+func Test_AnyAnyMap_TrackChanges(t *testing.T) {
+	sortKeys := func(keys []interface{}) []interface{} {
+		sort.Slice(keys, func(i, j int) bool { return keys[i].(int) < keys[j].(int) })
+		return keys
+	}
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewAnyAnyMapFrom(g.MapAnyAny{1: 1, 2: 2, 3: 3}, true)
+		changed, removed := m.FlushChanges()
+		t.Assert(changed, nil)
+		t.Assert(removed, nil)
+
+		m.TrackChanges(true)
+		m.Set(4, 4)
+		m.Set(1, 10)
+		m.Remove(2)
+		m.Remove(5)
+		m.Set(6, 6)
+		m.Remove(6)
+		m.Remove(3)
+		m.Set(3, 30)
+		changed, removed = m.FlushChanges()
+		t.Assert(sortKeys(changed), g.Slice{1, 3, 4})
+		t.Assert(sortKeys(removed), g.Slice{2, 6})
+
+		changed, removed = m.FlushChanges()
+		t.Assert(len(changed), 0)
+		t.Assert(len(removed), 0)
+
+		// Replacing the data map.
+		m.Replace(map[interface{}]interface{}{1: 1, 7: 7})
+		changed, removed = m.FlushChanges()
+		t.Assert(sortKeys(changed), g.Slice{1, 7})
+		t.Assert(sortKeys(removed), g.Slice{3, 4})
+		m.Clear()
+		changed, removed = m.FlushChanges()
+		t.Assert(len(changed), 0)
+		t.Assert(sortKeys(removed), g.Slice{1, 7})
+
+		t.AssertNil(m.UnmarshalValue(g.MapStrAny{"a": 1}))
+		changed, _ = m.FlushChanges()
+		t.Assert(changed, g.Slice{"a"})
+
+		// Enabling again keeps the recorded keys, and disabling discards them.
+		m.Set(8, 8)
+		m.TrackChanges(true)
+		changed, _ = m.FlushChanges()
+		t.Assert(changed, g.Slice{8})
+		m.Set(9, 9)
+		m.TrackChanges(false)
+		m.TrackChanges(true)
+		changed, _ = m.FlushChanges()
+		t.Assert(len(changed), 0)
+	})
+	// The nested setting by path records the top-level key.
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewFrom(g.MapAnyAny{"a": g.Map{"b": 1}, "c": 1}, true)
+		m.TrackChanges(true)
+		t.AssertNil(m.SetByPath("a.b", 2))
+		t.AssertNil(m.SetByPath("d.e", 3))
+		t.AssertNE(m.SetByPath("c.f", 4), nil)
+		changed, removed := m.FlushChanges()
+		sort.Slice(changed, func(i, j int) bool { return changed[i].(string) < changed[j].(string) })
+		t.Assert(changed, g.Slice{"a", "d"})
+		t.Assert(len(removed), 0)
+	})
+}
+
+func Test_AnyAnyMap_OverlayEnv(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		envs := map[string]string{
+			"GMAP_TEST_DB_HOST": "127.0.0.1",
+			"GMAP_TEST_DB_PORT": "3306",
+			"GMAP_TEST_NAME":    "",
+			"GMAP_TEST_":        "ignored",
+		}
+		for name, value := range envs {
+			t.AssertNil(os.Setenv(name, value))
+			defer os.Unsetenv(name)
+		}
+		m := gmap.NewAnyAnyMapFrom(g.MapAnyAny{"db.host": "localhost", "db.user": "root"}, true)
+		m.OverlayEnv("GMAP_TEST_")
+		t.Assert(m.Map(), g.MapAnyAny{
+			"db.host": "127.0.0.1",
+			"db.port": "3306",
+			"db.user": "root",
+			"name":    "",
+		})
+
+		// The leading underscores of the keys are trimmed.
+		m = gmap.New()
+		m.OverlayEnv("GMAP_TEST")
+		t.Assert(m.Get("db.port"), "3306")
+		t.Assert(m.Size(), 3)
+
+		m.OverlayEnv("GMAP_TEST_NOT_EXIST_")
+		t.Assert(m.Size(), 3)
+	})
+}
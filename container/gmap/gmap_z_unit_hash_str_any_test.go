@@ -417,3 +417,14 @@ func Test_StrAnyMap_Diff(t *testing.T) {
 		t.Assert(updatedKeys, []string{"3"})
 	})
 }
+
+func Test_StrAnyMap_ToMap(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewStrAnyMapFrom(g.MapStrAny{"a": 1, "b": 2}, true)
+		n := m.ToMap()
+		t.Assert(n.Map(), g.MapAnyAny{"a": 1, "b": 2})
+		t.Assert(n.Get("a"), 1)
+		n.Set("c", 3)
+		t.Assert(m.Size(), 2)
+	})
+}
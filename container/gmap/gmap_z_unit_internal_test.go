@@ -0,0 +1,29 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with gm file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gmap
+
+import (
+	"testing"
+
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+func Test_AnyAnyMap_SetByPath_Version(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := NewFrom(map[interface{}]interface{}{"a": map[string]interface{}{"b": 1}}, true)
+		// The view is created without freezing, to check that the nested setting invalidates it.
+		view := MapView{source: m, data: m.data, version: m.version.Val()}
+		t.Assert(view.Get("a"), map[string]interface{}{"b": 1})
+
+		t.AssertNil(m.SetByPath("a.b", 2))
+		t.Assert(m.version.Val() > view.version, true)
+		defer func() {
+			t.AssertNE(recover(), nil)
+		}()
+		view.Get("a")
+	})
+}
@@ -0,0 +1,127 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with gm file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gmap_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/gogf/gf/v2/container/gmap"
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+func Test_LRUMap_Var(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		var m gmap.LRUMap
+		t.Assert(m.Keys(), []interface{}{})
+		m.Set(1, 1)
+		m.Set(2, 2)
+		t.Assert(m.Get(1), 1)
+		t.Assert(m.Keys(), []interface{}{1, 2})
+		t.Assert(m.Size(), 2)
+		t.Assert(m.Cap(), 0)
+	})
+}
+
+func Test_LRUMap_Basic(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewLRUMap(3)
+		t.Assert(m.IsEmpty(), true)
+		m.Set(1, "1")
+		m.Set(2, "2")
+		m.Set(3, "3")
+		t.Assert(m.Keys(), []interface{}{3, 2, 1})
+
+		t.Assert(m.Get(1), "1")
+		t.Assert(m.Keys(), []interface{}{1, 3, 2})
+
+		v, found := m.Search(2)
+		t.Assert(found, true)
+		t.Assert(v, "2")
+		t.Assert(m.Keys(), []interface{}{2, 1, 3})
+
+		t.Assert(m.Peek(3), "3")
+		t.Assert(m.Contains(3), true)
+		t.Assert(m.Keys(), []interface{}{2, 1, 3})
+
+		m.Set(4, "4")
+		t.Assert(m.Size(), 3)
+		t.Assert(m.Contains(3), false)
+		t.Assert(m.Keys(), []interface{}{4, 2, 1})
+
+		m.Set(1, "one")
+		t.Assert(m.Keys(), []interface{}{1, 4, 2})
+		t.Assert(m.Peek(1), "one")
+
+		t.Assert(m.Remove(4), "4")
+		t.Assert(m.Remove(4), nil)
+		t.Assert(m.Keys(), []interface{}{1, 2})
+
+		_, found = m.Search(4)
+		t.Assert(found, false)
+
+		var values []interface{}
+		m.Iterator(func(key, value interface{}) bool {
+			values = append(values, value)
+			return true
+		})
+		t.Assert(values, []interface{}{"one", "2"})
+
+		m.Clear()
+		t.Assert(m.Size(), 0)
+		t.Assert(m.Keys(), []interface{}{})
+	})
+}
+
+func Test_LRUMap_OnEvict(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			m       = gmap.NewLRUMap(2)
+			evicted = make(map[interface{}]interface{})
+		)
+		m.OnEvict(func(key, value interface{}) {
+			evicted[key] = value
+			// It is safe calling the map in the callback.
+			t.Assert(m.Contains(key), false)
+		})
+		m.Set(1, 1)
+		m.Set(2, 2)
+		m.Get(1)
+		m.Set(3, 3)
+		t.Assert(evicted, map[interface{}]interface{}{2: 2})
+
+		m.Sets(map[interface{}]interface{}{4: 4, 5: 5})
+		t.Assert(m.Size(), 2)
+		t.Assert(len(evicted), 3)
+
+		m.Remove(4)
+		m.Remove(5)
+		t.Assert(len(evicted), 3)
+	})
+}
+
+func Test_LRUMap_Concurrent(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			m  = gmap.NewLRUMap(100, true)
+			wg = sync.WaitGroup{}
+		)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 1000; j++ {
+					m.Set(i*1000+j, j)
+					m.Get(i*1000 + j - 1)
+				}
+			}(i)
+		}
+		wg.Wait()
+		t.Assert(m.Size(), 100)
+		t.Assert(len(m.Keys()), 100)
+	})
+}
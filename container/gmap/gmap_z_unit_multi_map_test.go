@@ -0,0 +1,72 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with gm file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gmap_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/gogf/gf/v2/container/gmap"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+func Test_MultiMap_Basic(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		var m gmap.MultiMap
+		m.Append("a", 1)
+		m.Append("a", 2, 3)
+		m.Append("b", g.Slice{1})
+		t.Assert(m.Size(), 2)
+		t.Assert(m.GetSlice("a"), g.Slice{1, 2, 3})
+		t.Assert(m.GetSlice("z"), nil)
+		t.Assert(m.Count("a"), 3)
+		t.Assert(m.Contains("b"), true)
+
+		// The returned slice is a copy.
+		values := m.GetSlice("a")
+		values[0] = 100
+		t.Assert(m.GetSlice("a"), g.Slice{1, 2, 3})
+
+		t.Assert(m.RemoveValue("a", 2), true)
+		t.Assert(m.RemoveValue("a", 2), false)
+		t.Assert(m.GetSlice("a"), g.Slice{1, 3})
+		t.Assert(m.RemoveValue("b", g.Slice{1}), true)
+		t.Assert(m.Contains("b"), false)
+
+		t.Assert(m.Remove("a"), g.Slice{1, 3})
+		t.Assert(m.Remove("a"), nil)
+		t.Assert(m.IsEmpty(), true)
+	})
+}
+
+func Test_MultiMap_Concurrent(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			m  = gmap.NewMultiMap(true)
+			wg sync.WaitGroup
+		)
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				m.Append(i%10, i)
+			}(i)
+		}
+		wg.Wait()
+		t.Assert(m.Size(), 10)
+		total := 0
+		m.Iterator(func(key interface{}, values []interface{}) bool {
+			total += len(values)
+			return true
+		})
+		t.Assert(total, 100)
+		t.Assert(len(m.Keys()), 10)
+		m.Clear()
+		t.Assert(m.Size(), 0)
+	})
+}
@@ -0,0 +1,119 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with gm file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gmap_test
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gogf/gf/v2/container/gmap"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/grand"
+)
+
+func Test_TrieMap_Var(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		var m gmap.TrieMap
+		t.Assert(m.Keys(), []string{})
+		_, _, found := m.LongestPrefix("a")
+		t.Assert(found, false)
+		t.Assert(m.WithPrefix("a"), g.MapStrAny{})
+		t.Assert(m.Remove("a"), nil)
+		m.Set("a", 1)
+		t.Assert(m.Get("a"), 1)
+		t.Assert(m.Size(), 1)
+	})
+}
+
+func Test_TrieMap_Basic(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewTrieMap(true)
+		m.Set("/api/users", 1)
+		m.Set("/api", 2)
+		m.Set("/api/user", 3)
+		m.Set("/static", 4)
+		m.Set("/api/users", 10)
+		t.Assert(m.Size(), 4)
+		t.Assert(m.Keys(), []string{"/api", "/api/user", "/api/users", "/static"})
+		t.Assert(m.Get("/api/users"), 10)
+		t.Assert(m.Get("/api/"), nil)
+		t.Assert(m.Contains("/api/"), false)
+		t.Assert(m.Contains("/api/user"), true)
+
+		key, value, found := m.LongestPrefix("/api/users/1")
+		t.Assert(key, "/api/users")
+		t.Assert(value, 10)
+		t.Assert(found, true)
+		key, value, found = m.LongestPrefix("/api/us")
+		t.Assert(key, "/api")
+		t.Assert(value, 2)
+		t.Assert(found, true)
+		_, _, found = m.LongestPrefix("/ap")
+		t.Assert(found, false)
+
+		t.Assert(m.WithPrefix("/api/u"), g.MapStrAny{"/api/user": 3, "/api/users": 10})
+		t.Assert(m.WithPrefix("/api"), g.MapStrAny{"/api": 2, "/api/user": 3, "/api/users": 10})
+		t.Assert(m.WithPrefix("/x"), g.MapStrAny{})
+		t.Assert(len(m.WithPrefix("")), 4)
+
+		t.Assert(m.Remove("/api/"), nil)
+		t.Assert(m.Remove("/api/user"), 3)
+		t.Assert(m.Remove("/api/user"), nil)
+		t.Assert(m.Remove("/api"), 2)
+		t.Assert(m.Map(), g.MapStrAny{"/api/users": 10, "/static": 4})
+		key, _, _ = m.LongestPrefix("/api/users/1")
+		t.Assert(key, "/api/users")
+
+		m.Set("", 0)
+		key, value, found = m.LongestPrefix("/x")
+		t.Assert(key, "")
+		t.Assert(value, 0)
+		t.Assert(found, true)
+		t.Assert(m.Remove(""), 0)
+
+		m.Clear()
+		t.Assert(m.IsEmpty(), true)
+	})
+}
+
+func Test_TrieMap_Random(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			m    = gmap.NewTrieMap()
+			data = make(map[string]interface{})
+		)
+		for i := 0; i < 2000; i++ {
+			key := grand.Str("abc", grand.N(0, 6))
+			if grand.N(0, 2) == 0 {
+				delete(data, key)
+				m.Remove(key)
+			} else {
+				data[key] = i
+				m.Set(key, i)
+			}
+		}
+		t.Assert(m.Map(), data)
+		t.Assert(m.Size(), len(data))
+		keys := make([]string, 0, len(data))
+		for key := range data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		t.Assert(m.Keys(), keys)
+		for _, prefix := range []string{"a", "ab", "cba", "aaaa"} {
+			expect := make(map[string]interface{})
+			for k, v := range data {
+				if strings.HasPrefix(k, prefix) {
+					expect[k] = v
+				}
+			}
+			t.Assert(m.WithPrefix(prefix), expect)
+		}
+	})
+}
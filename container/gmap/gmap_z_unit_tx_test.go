@@ -0,0 +1,60 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with gm file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gmap_test
+
+import (
+	"testing"
+
+	"github.com/gogf/gf/v2/container/gmap"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+func Test_Tx_Rollback(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewFrom(g.MapAnyAny{1: "a", 2: "b", 3: nil}, true)
+		tx := m.Begin()
+		tx.Set(1, "x")
+		tx.Set(1, "y")
+		tx.Set(4, "d")
+		t.Assert(tx.Remove(2), "b")
+		t.Assert(tx.Remove(5), nil)
+		tx.Set(2, "z")
+		t.Assert(tx.Remove(3), nil)
+		// The changes are visible before committing.
+		t.Assert(m.Map(), g.MapAnyAny{1: "y", 2: "z", 4: "d"})
+
+		tx.Rollback()
+		t.Assert(m.Map(), g.MapAnyAny{1: "a", 2: "b", 3: nil})
+		tx.Rollback()
+		tx.Commit()
+		t.Assert(m.Map(), g.MapAnyAny{1: "a", 2: "b", 3: nil})
+	})
+}
+
+func Test_Tx_Commit(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewFrom(g.MapAnyAny{1: "a"})
+		tx := m.Begin()
+		tx.Set(2, "b")
+		tx.Remove(1)
+		tx.Commit()
+		tx.Rollback()
+		t.Assert(m.Map(), g.MapAnyAny{2: "b"})
+
+		defer func() {
+			t.AssertNE(recover(), nil)
+		}()
+		tx.Set(3, "c")
+	})
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.New()
+		tx := m.Begin()
+		tx.Rollback()
+		t.Assert(m.Size(), 0)
+	})
+}
@@ -0,0 +1,90 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with gm file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gmap_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/gogf/gf/v2/container/gmap"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+func Test_MapView_Basic(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewFrom(g.MapAnyAny{1: "a", 2: "b", 3: "c"}, true)
+		m.Freeze()
+		view := m.View()
+		t.Assert(view.Size(), 3)
+		t.Assert(view.Get(1), "a")
+		t.Assert(view.Get(4), nil)
+		value, found := view.Search(2)
+		t.Assert(value, "b")
+		t.Assert(found, true)
+		_, found = view.Search(4)
+		t.Assert(found, false)
+		t.Assert(view.Contains(3), true)
+		t.Assert(view.Contains(4), false)
+
+		keys := view.Keys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].(int) < keys[j].(int) })
+		t.Assert(keys, g.Slice{1, 2, 3})
+		values := view.Values()
+		sort.Slice(values, func(i, j int) bool { return values[i].(string) < values[j].(string) })
+		t.Assert(values, g.Slice{"a", "b", "c"})
+
+		data := make(map[interface{}]interface{})
+		view.Iterator(func(k interface{}, v interface{}) bool {
+			data[k] = v
+			return true
+		})
+		t.Assert(data, m.Map())
+		count := 0
+		view.Iterator(func(k interface{}, v interface{}) bool {
+			count++
+			return false
+		})
+		t.Assert(count, 1)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.New()
+		m.Freeze()
+		t.Assert(m.View().Size(), 0)
+		t.Assert(m.View().Get(1), nil)
+	})
+}
+
+func Test_MapView_Panic(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gmap.NewFrom(g.MapAnyAny{1: "a"})
+		defer func() {
+			t.AssertNE(recover(), nil)
+		}()
+		m.View()
+	})
+	// The map is changed by GetOrLoad in progress when it is frozen.
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			m    = gmap.NewFrom(g.MapAnyAny{1: "a"}, true)
+			view gmap.MapView
+		)
+		_, err := m.GetOrLoad(2, func(key interface{}) (interface{}, error) {
+			m.Freeze()
+			view = m.View()
+			t.Assert(view.Get(1), "a")
+			return "b", nil
+		})
+		t.AssertNil(err)
+		t.Assert(m.Get(2), "b")
+		t.Assert(m.View().Get(2), "b")
+		defer func() {
+			t.AssertNE(recover(), nil)
+		}()
+		view.Get(1)
+	})
+}
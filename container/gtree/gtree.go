@@ -8,3 +8,9 @@
 //
 // Some implements are from: https://github.com/emirpasic/gods
 package gtree
+
+// Entry is a key-value item of the tree.
+type Entry struct {
+	Key   interface{}
+	Value interface{}
+}
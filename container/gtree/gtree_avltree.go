@@ -264,6 +264,13 @@ func (tree *AVLTree) Size() int {
 	return tree.size
 }
 
+// Height returns the height of the tree, which is 0 if the tree is empty.
+func (tree *AVLTree) Height() int {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+	return tree.root.height()
+}
+
 // Keys returns all keys in asc order.
 func (tree *AVLTree) Keys() []interface{} {
 	keys := make([]interface{}, tree.Size())
@@ -584,7 +591,8 @@ func (tree *AVLTree) remove(key interface{}, qp **AVLTreeNode) (value interface{
 		if removeMin(&q.children[1], &q.Key, &q.Value) {
 			return value, removeFix(-1, qp)
 		}
-		return
+		// The height of the right subtree is not changed, so there's no need fixing upward.
+		return value, false
 	}
 
 	if c < 0 {
@@ -755,6 +763,20 @@ func (node *AVLTreeNode) walk1(a int) *AVLTreeNode {
 	return p
 }
 
+// height returns the height of the subtree rooted at `node`.
+// It walks down along the higher child of each node using the balance factor.
+func (node *AVLTreeNode) height() int {
+	h := 0
+	for n := node; n != nil; h++ {
+		if n.b > 0 {
+			n = n.children[1]
+		} else {
+			n = n.children[0]
+		}
+	}
+	return h
+}
+
 func output(node *AVLTreeNode, prefix string, isTail bool, str *string) {
 	if node.children[1] != nil {
 		newPrefix := prefix
@@ -0,0 +1,113 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gtree
+
+import (
+	"math"
+	"strings"
+
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+// The comparators below can be used as the key comparator of the trees, like:
+//
+//	tree := gtree.NewRedBlackTree(gtree.ComparatorInt)
+//
+// They compare the values using comparison operators but not subtraction, so that they never overflow
+// for large values. The nil value is less than any other value, and two nil values are equal.
+
+// ComparatorInt compares `a` and `b` as int.
+func ComparatorInt(a, b interface{}) int {
+	if result, ok := compareNil(a, b); ok {
+		return result
+	}
+	return compareOrdered(gconv.Int(a), gconv.Int(b))
+}
+
+// ComparatorInt64 compares `a` and `b` as int64.
+func ComparatorInt64(a, b interface{}) int {
+	if result, ok := compareNil(a, b); ok {
+		return result
+	}
+	return compareOrdered(gconv.Int64(a), gconv.Int64(b))
+}
+
+// ComparatorString compares `a` and `b` as string.
+func ComparatorString(a, b interface{}) int {
+	if result, ok := compareNil(a, b); ok {
+		return result
+	}
+	return strings.Compare(gconv.String(a), gconv.String(b))
+}
+
+// ComparatorFloat64 compares `a` and `b` as float64.
+// The NaN value is less than any other float value, and two NaN values are equal.
+func ComparatorFloat64(a, b interface{}) int {
+	if result, ok := compareNil(a, b); ok {
+		return result
+	}
+	var (
+		aFloat = gconv.Float64(a)
+		bFloat = gconv.Float64(b)
+		aNaN   = math.IsNaN(aFloat)
+		bNaN   = math.IsNaN(bFloat)
+	)
+	switch {
+	case aNaN && bNaN:
+		return 0
+	case aNaN:
+		return -1
+	case bNaN:
+		return 1
+	}
+	return compareOrdered(aFloat, bFloat)
+}
+
+// ComparatorTime compares `a` and `b` as time.Time.
+func ComparatorTime(a, b interface{}) int {
+	if result, ok := compareNil(a, b); ok {
+		return result
+	}
+	var (
+		aTime = gconv.Time(a)
+		bTime = gconv.Time(b)
+	)
+	switch {
+	case aTime.Before(bTime):
+		return -1
+	case aTime.After(bTime):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compareNil compares `a` and `b` if any of them is nil, in which nil is less than any other value.
+// The second return parameter `ok` is false if neither of them is nil.
+func compareNil(a, b interface{}) (result int, ok bool) {
+	switch {
+	case a == nil && b == nil:
+		return 0, true
+	case a == nil:
+		return -1, true
+	case b == nil:
+		return 1, true
+	}
+	return 0, false
+}
+
+// compareOrdered compares ordered values `a` and `b` using comparison operators.
+func compareOrdered[T int | int64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
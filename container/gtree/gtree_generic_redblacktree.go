@@ -0,0 +1,157 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gtree
+
+// GRedBlackTree is a red-black tree with type parameters, which wraps RedBlackTree
+// and provides typed keys and values, and a typed key comparator.
+type GRedBlackTree[K, V any] struct {
+	tree *RedBlackTree
+}
+
+// NewGRedBlackTree instantiates a generic red-black tree with the custom key comparator.
+// The parameter `safe` is used to specify whether using tree in concurrent-safety,
+// which is false in default.
+func NewGRedBlackTree[K, V any](comparator func(v1, v2 K) int, safe ...bool) *GRedBlackTree[K, V] {
+	return &GRedBlackTree[K, V]{
+		tree: NewRedBlackTree(func(v1, v2 interface{}) int {
+			return comparator(v1.(K), v2.(K))
+		}, safe...),
+	}
+}
+
+// Set inserts key-value item into the tree.
+func (tree *GRedBlackTree[K, V]) Set(key K, value V) {
+	tree.tree.Set(key, value)
+}
+
+// Search searches the tree with given `key`.
+// Second return parameter `found` is true if key was found, otherwise false.
+func (tree *GRedBlackTree[K, V]) Search(key K) (value V, found bool) {
+	var v interface{}
+	if v, found = tree.tree.Search(key); found {
+		value, _ = v.(V)
+	}
+	return
+}
+
+// Get searches the node in the tree by `key` and returns its value,
+// or the zero value of V if `key` is not found in tree.
+func (tree *GRedBlackTree[K, V]) Get(key K) (value V) {
+	value, _ = tree.Search(key)
+	return
+}
+
+// Contains checks whether `key` exists in the tree.
+func (tree *GRedBlackTree[K, V]) Contains(key K) bool {
+	return tree.tree.Contains(key)
+}
+
+// Remove removes the node from the tree by `key`, and returns its value.
+// Second return parameter `found` is true if key was found, otherwise false.
+func (tree *GRedBlackTree[K, V]) Remove(key K) (value V, found bool) {
+	tree.tree.mu.Lock()
+	defer tree.tree.mu.Unlock()
+	if _, found = tree.tree.doSearch(key); found {
+		value, _ = tree.tree.doRemove(key).(V)
+	}
+	return
+}
+
+// Size returns number of nodes in the tree.
+func (tree *GRedBlackTree[K, V]) Size() int {
+	return tree.tree.Size()
+}
+
+// IsEmpty returns true if tree does not contain any nodes.
+func (tree *GRedBlackTree[K, V]) IsEmpty() bool {
+	return tree.tree.IsEmpty()
+}
+
+// Keys returns all keys in asc order.
+func (tree *GRedBlackTree[K, V]) Keys() []K {
+	keys := make([]K, 0, tree.Size())
+	tree.IteratorAsc(func(key K, value V) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
+
+// Values returns all values in asc order based on the key.
+func (tree *GRedBlackTree[K, V]) Values() []V {
+	values := make([]V, 0, tree.Size())
+	tree.IteratorAsc(func(key K, value V) bool {
+		values = append(values, value)
+		return true
+	})
+	return values
+}
+
+// Left returns the left-most (min) key-value item of the tree.
+// The returned `found` is false if tree is empty.
+func (tree *GRedBlackTree[K, V]) Left() (key K, value V, found bool) {
+	return tree.node(tree.tree.Left())
+}
+
+// Right returns the right-most (max) key-value item of the tree.
+// The returned `found` is false if tree is empty.
+func (tree *GRedBlackTree[K, V]) Right() (key K, value V, found bool) {
+	return tree.node(tree.tree.Right())
+}
+
+// Min returns the minimum key-value item of the tree, which is the same as Left.
+// The returned `found` is false if tree is empty, in which `key` and `value` are the zero values.
+func (tree *GRedBlackTree[K, V]) Min() (key K, value V, found bool) {
+	return tree.Left()
+}
+
+// Max returns the maximum key-value item of the tree, which is the same as Right.
+// The returned `found` is false if tree is empty, in which `key` and `value` are the zero values.
+func (tree *GRedBlackTree[K, V]) Max() (key K, value V, found bool) {
+	return tree.Right()
+}
+
+// IteratorAsc iterates the tree readonly in ascending order with given callback function `f`.
+// If `f` returns true, then it continues iterating; or false to stop.
+func (tree *GRedBlackTree[K, V]) IteratorAsc(f func(key K, value V) bool) {
+	tree.tree.IteratorAsc(func(key, value interface{}) bool {
+		v, _ := value.(V)
+		return f(key.(K), v)
+	})
+}
+
+// IteratorDesc iterates the tree readonly in descending order with given callback function `f`.
+// If `f` returns true, then it continues iterating; or false to stop.
+func (tree *GRedBlackTree[K, V]) IteratorDesc(f func(key K, value V) bool) {
+	tree.tree.IteratorDesc(func(key, value interface{}) bool {
+		v, _ := value.(V)
+		return f(key.(K), v)
+	})
+}
+
+// Clear removes all nodes from the tree.
+func (tree *GRedBlackTree[K, V]) Clear() {
+	tree.tree.Clear()
+}
+
+// String returns a string representation of container.
+func (tree *GRedBlackTree[K, V]) String() string {
+	if tree == nil {
+		return ""
+	}
+	return tree.tree.String()
+}
+
+// node converts `node` to typed key-value item.
+func (tree *GRedBlackTree[K, V]) node(node *RedBlackTreeNode) (key K, value V, found bool) {
+	if node == nil {
+		return
+	}
+	key = node.Key.(K)
+	value, _ = node.Value.(V)
+	return key, value, true
+}
@@ -8,9 +8,16 @@ package gtree
 
 import (
 	"bytes"
+	"encoding/gob"
 	"fmt"
+	"io"
+	"math/bits"
+	"os"
+	"sort"
 
 	"github.com/gogf/gf/v2/container/gvar"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
 	"github.com/gogf/gf/v2/internal/json"
 	"github.com/gogf/gf/v2/internal/rwmutex"
 	"github.com/gogf/gf/v2/util/gconv"
@@ -24,11 +31,15 @@ const (
 )
 
 // RedBlackTree holds elements of the red-black tree.
+//
+// It is concurrent-safe if it is created with `safe` true, in which all the writing operations
+// are within RWMutex.Lock and all the reading operations are within RWMutex.RLock, just like gmap.
 type RedBlackTree struct {
 	mu         rwmutex.RWMutex
 	root       *RedBlackTreeNode
 	size       int
 	comparator func(v1, v2 interface{}) int
+	shared     bool // shared marks the nodes are shared with other trees by COWClone, which are copied before changing.
 }
 
 // RedBlackTreeNode is a single element within the tree.
@@ -36,6 +47,7 @@ type RedBlackTreeNode struct {
 	Key    interface{}
 	Value  interface{}
 	color  color
+	size   int // size is the number of nodes in the subtree rooted at this node.
 	left   *RedBlackTreeNode
 	right  *RedBlackTreeNode
 	parent *RedBlackTreeNode
@@ -66,29 +78,145 @@ func NewRedBlackTreeFrom(comparator func(v1, v2 interface{}) int, data map[inter
 func (tree *RedBlackTree) SetComparator(comparator func(a, b interface{}) int) {
 	tree.mu.Lock()
 	defer tree.mu.Unlock()
+	if tree.size == 0 {
+		tree.comparator = comparator
+		return
+	}
+	// Resort the tree if comparator is changed.
+	tree.doResort(comparator)
+}
+
+// Resort changes the comparator of the tree to `comparator` and rebuilds the tree in the order of
+// the new comparator within one RWMutex.Lock. The nodes are rebuilt bottom-up as BuildFromSorted,
+// so the tree is balanced after resorting.
+//
+// If several keys are equal by the new comparator, only the item having the greatest key by the
+// old comparator is kept.
+func (tree *RedBlackTree) Resort(comparator func(a, b interface{}) int) {
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+	tree.doResort(comparator)
+}
+
+// doResort changes the comparator of the tree to `comparator` and rebuilds the tree without mutex.
+func (tree *RedBlackTree) doResort(comparator func(a, b interface{}) int) {
+	// The entries are retrieved before changing the comparator, as iterating the tree needs the old one.
+	entries := tree.doFlatten()
 	tree.comparator = comparator
-	if tree.size > 0 {
-		data := make(map[interface{}]interface{}, tree.size)
-		tree.doIteratorAsc(tree.leftNode(), func(key, value interface{}) bool {
-			data[key] = value
-			return true
-		})
-		// Resort the tree if comparator is changed.
-		tree.root = nil
-		tree.size = 0
-		for k, v := range data {
-			tree.doSet(k, v)
+	tree.doBuildFromEntries(entries)
+}
+
+// doBuildFromEntries replaces the data of the tree with `entries` in any order without mutex,
+// which sorts `entries` in place by the comparator of the tree and builds the tree bottom-up.
+// If several keys are equal, only the last one of them in `entries` is kept.
+func (tree *RedBlackTree) doBuildFromEntries(entries []Entry) {
+	comparator := tree.getComparator()
+	sort.SliceStable(entries, func(i, j int) bool {
+		return comparator(entries[i].Key, entries[j].Key) < 0
+	})
+	var (
+		keys   = make([]interface{}, 0, len(entries))
+		values = make([]interface{}, 0, len(entries))
+	)
+	for _, entry := range entries {
+		if n := len(keys); n > 0 && comparator(keys[n-1], entry.Key) == 0 {
+			keys[n-1], values[n-1] = entry.Key, entry.Value
+			continue
 		}
+		keys = append(keys, entry.Key)
+		values = append(values, entry.Value)
 	}
+	tree.doBuildFromSorted(keys, values)
+}
+
+// Merge sets all the key-value items of `other` to the tree, in which the values of `other` overwrite
+// the values of the same keys in the tree, and the keys are ordered by the comparator of the tree.
+// The items of `other` are retrieved within its RWMutex.RLock, and then set within one RWMutex.Lock of the tree.
+//
+// If `other` is not much smaller than the tree, the tree is rebuilt bottom-up from the combined sorted items
+// like BuildFromSorted, which is faster than inserting the items one by one.
+func (tree *RedBlackTree) Merge(other *RedBlackTree) {
+	if other == nil || other == tree {
+		return
+	}
+	other.mu.RLock()
+	entries := other.doFlatten()
+	other.mu.RUnlock()
+	if len(entries) == 0 {
+		return
+	}
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+	if len(entries)*4 < tree.size {
+		for _, entry := range entries {
+			tree.doSet(entry.Key, entry.Value)
+		}
+		return
+	}
+	// The stable sorting keeps the items of `other` after the items of the same keys in the tree.
+	tree.doBuildFromEntries(append(tree.doFlatten(), entries...))
 }
 
 // Clone returns a new tree with a copy of current tree.
 func (tree *RedBlackTree) Clone() *RedBlackTree {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
 	newTree := NewRedBlackTree(tree.comparator, tree.mu.IsSafe())
-	newTree.Sets(tree.Map())
+	tree.doIteratorAsc(tree.leftNode(), func(key, value interface{}) bool {
+		newTree.doSet(key, value)
+		return true
+	})
 	return newTree
 }
 
+// COWClone returns a new tree sharing the nodes with current tree, which is copy-on-write.
+// It is nearly free as no node is copied, and the nodes are copied lazily by the first change on either
+// side of the current tree and the returned tree, so that changes on one side are not visible on the other side.
+//
+// Note that the values are shared just like Clone, and changing the nodes returned by Left, Right, Floor
+// and Ceiling directly in non-concurrent-safe usage breaks the isolation.
+func (tree *RedBlackTree) COWClone() *RedBlackTree {
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+	if tree.root != nil {
+		tree.shared = true
+	}
+	return &RedBlackTree{
+		mu:         rwmutex.Create(tree.mu.IsSafe()),
+		root:       tree.root,
+		size:       tree.size,
+		comparator: tree.comparator,
+		shared:     tree.shared,
+	}
+}
+
+// detach copies the nodes of the tree without mutex if they are shared by COWClone,
+// so that the nodes can be changed exclusively. It should be called before changing the nodes.
+func (tree *RedBlackTree) detach() {
+	if !tree.shared {
+		return
+	}
+	tree.root = copyNode(tree.root, nil)
+	tree.shared = false
+}
+
+// copyNode returns a deep copy of the subtree rooted at `node`, of which the root node has parent `parent`.
+func copyNode(node, parent *RedBlackTreeNode) *RedBlackTreeNode {
+	if node == nil {
+		return nil
+	}
+	newNode := &RedBlackTreeNode{
+		Key:    node.Key,
+		Value:  node.Value,
+		color:  node.color,
+		size:   node.size,
+		parent: parent,
+	}
+	newNode.left = copyNode(node.left, newNode)
+	newNode.right = copyNode(node.right, newNode)
+	return newNode
+}
+
 // Set inserts key-value item into the tree.
 func (tree *RedBlackTree) Set(key interface{}, value interface{}) {
 	tree.mu.Lock()
@@ -107,11 +235,12 @@ func (tree *RedBlackTree) Sets(data map[interface{}]interface{}) {
 
 // doSet inserts key-value item into the tree without mutex.
 func (tree *RedBlackTree) doSet(key interface{}, value interface{}) {
+	tree.detach()
 	insertedNode := (*RedBlackTreeNode)(nil)
 	if tree.root == nil {
 		// Assert key is of comparator's type for initial tree
 		tree.getComparator()(key, key)
-		tree.root = &RedBlackTreeNode{Key: key, Value: value, color: red}
+		tree.root = &RedBlackTreeNode{Key: key, Value: value, color: red, size: 1}
 		insertedNode = tree.root
 	} else {
 		node := tree.root
@@ -125,7 +254,7 @@ func (tree *RedBlackTree) doSet(key interface{}, value interface{}) {
 				return
 			case compare < 0:
 				if node.left == nil {
-					node.left = &RedBlackTreeNode{Key: key, Value: value, color: red}
+					node.left = &RedBlackTreeNode{Key: key, Value: value, color: red, size: 1}
 					insertedNode = node.left
 					loop = false
 				} else {
@@ -133,7 +262,7 @@ func (tree *RedBlackTree) doSet(key interface{}, value interface{}) {
 				}
 			case compare > 0:
 				if node.right == nil {
-					node.right = &RedBlackTreeNode{Key: key, Value: value, color: red}
+					node.right = &RedBlackTreeNode{Key: key, Value: value, color: red, size: 1}
 					insertedNode = node.right
 					loop = false
 				} else {
@@ -142,6 +271,9 @@ func (tree *RedBlackTree) doSet(key interface{}, value interface{}) {
 			}
 		}
 		insertedNode.parent = node
+		for ; node != nil; node = node.parent {
+			node.size++
+		}
 	}
 	tree.insertCase1(insertedNode)
 	tree.size++
@@ -153,12 +285,21 @@ func (tree *RedBlackTree) Get(key interface{}) (value interface{}) {
 	return
 }
 
+// GetOrDefault returns the value by given `key`, or returns `def` if the `key` does not exist.
+// It returns the stored value even if it is nil, and it never changes the tree unlike GetOrSet.
+func (tree *RedBlackTree) GetOrDefault(key interface{}, def interface{}) interface{} {
+	if v, ok := tree.Search(key); ok {
+		return v
+	}
+	return def
+}
+
 // doSetWithLockCheck checks whether value of the key exists with mutex.Lock,
 // if not exists, set value to the map with given `key`,
 // or else just return the existing value.
 //
 // When setting value, if `value` is type of <func() interface {}>,
-// it will be executed with mutex.Lock of the hash map,
+// it will be executed with mutex.Lock of the tree,
 // and its return value will be set to the map with `key`.
 //
 // It returns value with given `key`.
@@ -203,7 +344,7 @@ func (tree *RedBlackTree) GetOrSetFunc(key interface{}, f func() interface{}) in
 // and then returns this value.
 //
 // GetOrSetFuncLock differs with GetOrSetFunc function is that it executes function `f`
-// with mutex.Lock of the hash map.
+// with mutex.Lock of the tree.
 func (tree *RedBlackTree) GetOrSetFuncLock(key interface{}, f func() interface{}) interface{} {
 	if v, ok := tree.Search(key); !ok {
 		return tree.doSetWithLockCheck(key, f)
@@ -212,6 +353,40 @@ func (tree *RedBlackTree) GetOrSetFuncLock(key interface{}, f func() interface{}
 	}
 }
 
+// UpdateFunc updates the value of `key` with the return value of callback function `f` atomically,
+// which is called with the current value `old` of `key`, and `found` reporting whether `key` exists.
+// If the returned `keep` is false, `key` is removed from the tree, or else it is set with the returned
+// `new`, which inserts `key` if it does not exist. It returns the resulting value, which is nil if removed.
+//
+// The function `f` is executed with mutex.Lock of the tree, so it must not access the tree.
+func (tree *RedBlackTree) UpdateFunc(
+	key interface{}, f func(old interface{}, found bool) (new interface{}, keep bool),
+) interface{} {
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+	var (
+		old         interface{}
+		node, found = tree.doSearch(key)
+	)
+	if found {
+		old = node.Value
+	}
+	value, keep := f(old, found)
+	switch {
+	case !keep:
+		if found {
+			tree.doRemove(key)
+		}
+		return nil
+	case found && !tree.shared:
+		// The key is unchanged, so the value is updated in place without rebalancing.
+		node.Value = value
+	default:
+		tree.doSet(key, value)
+	}
+	return value
+}
+
 // GetVar returns a gvar.Var with the value by given `key`.
 // The returned gvar.Var is un-concurrent safe.
 func (tree *RedBlackTree) GetVar(key interface{}) *gvar.Var {
@@ -240,18 +415,19 @@ func (tree *RedBlackTree) GetVarOrSetFuncLock(key interface{}, f func() interfac
 // It returns false if `key` exists, and `value` would be ignored.
 func (tree *RedBlackTree) SetIfNotExist(key interface{}, value interface{}) bool {
 	if !tree.Contains(key) {
-		tree.doSetWithLockCheck(key, value)
-		return true
+		return tree.doSetIfNotExistWithLockCheck(key, value)
 	}
 	return false
 }
 
 // SetIfNotExistFunc sets value with return value of callback function `f`, and then returns true.
 // It returns false if `key` exists, and `value` would be ignored.
+//
+// The function `f` is executed with mutex.Lock of the tree only if the `key` does not exist,
+// so it is never executed concurrently for the same tree.
 func (tree *RedBlackTree) SetIfNotExistFunc(key interface{}, f func() interface{}) bool {
 	if !tree.Contains(key) {
-		tree.doSetWithLockCheck(key, f())
-		return true
+		return tree.doSetIfNotExistWithLockCheck(key, f)
 	}
 	return false
 }
@@ -259,16 +435,33 @@ func (tree *RedBlackTree) SetIfNotExistFunc(key interface{}, f func() interface{
 // SetIfNotExistFuncLock sets value with return value of callback function `f`, and then returns true.
 // It returns false if `key` exists, and `value` would be ignored.
 //
-// SetIfNotExistFuncLock differs with SetIfNotExistFunc function is that
-// it executes function `f` with mutex.Lock of the hash map.
+// SetIfNotExistFuncLock executes function `f` with mutex.Lock of the tree, the same as SetIfNotExistFunc.
 func (tree *RedBlackTree) SetIfNotExistFuncLock(key interface{}, f func() interface{}) bool {
 	if !tree.Contains(key) {
-		tree.doSetWithLockCheck(key, f)
-		return true
+		return tree.doSetIfNotExistWithLockCheck(key, f)
 	}
 	return false
 }
 
+// doSetIfNotExistWithLockCheck checks whether the `key` exists with mutex.Lock,
+// if not exists, it sets `value` to the tree with given `key` and returns true,
+// or else it returns false and `value` would be ignored.
+//
+// If `value` is type of <func() interface {}>, it will be executed with mutex.Lock
+// only if the `key` does not exist, and its return value will be set to the tree with `key`.
+func (tree *RedBlackTree) doSetIfNotExistWithLockCheck(key interface{}, value interface{}) bool {
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+	if _, found := tree.doSearch(key); found {
+		return false
+	}
+	if f, ok := value.(func() interface{}); ok {
+		value = f()
+	}
+	tree.doSet(key, value)
+	return true
+}
+
 // Contains checks whether `key` exists in the tree.
 func (tree *RedBlackTree) Contains(key interface{}) bool {
 	_, ok := tree.Search(key)
@@ -282,6 +475,10 @@ func (tree *RedBlackTree) doRemove(key interface{}) (value interface{}) {
 	if !found {
 		return
 	}
+	if tree.shared {
+		tree.detach()
+		node, _ = tree.doSearch(key)
+	}
 	value = node.Value
 	if node.left != nil && node.right != nil {
 		p := node.left.maximumNode()
@@ -303,6 +500,9 @@ func (tree *RedBlackTree) doRemove(key interface{}) (value interface{}) {
 		if node.parent == nil && child != nil {
 			child.color = black
 		}
+		for p := node.parent; p != nil; p = p.parent {
+			p.size--
+		}
 	}
 	tree.size--
 	return
@@ -315,7 +515,8 @@ func (tree *RedBlackTree) Remove(key interface{}) (value interface{}) {
 	return tree.doRemove(key)
 }
 
-// Removes batch deletes values of the tree by `keys`.
+// Removes batch deletes values of the tree by `keys` within one RWMutex.Lock.
+// The keys that do not exist in the tree are ignored.
 func (tree *RedBlackTree) Removes(keys []interface{}) {
 	tree.mu.Lock()
 	defer tree.mu.Unlock()
@@ -324,6 +525,43 @@ func (tree *RedBlackTree) Removes(keys []interface{}) {
 	}
 }
 
+// RemoveMin removes the node with the minimum key from the tree, and returns its key and value.
+// The returned `found` is false if tree is empty.
+func (tree *RedBlackTree) RemoveMin() (key, value interface{}, found bool) {
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+	if node := tree.leftNode(); node != nil {
+		key = node.Key
+		return key, tree.doRemove(key), true
+	}
+	return nil, nil, false
+}
+
+// RemoveMax removes the node with the maximum key from the tree, and returns its key and value.
+// The returned `found` is false if tree is empty.
+func (tree *RedBlackTree) RemoveMax() (key, value interface{}, found bool) {
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+	if node := tree.rightNode(); node != nil {
+		key = node.Key
+		return key, tree.doRemove(key), true
+	}
+	return nil, nil, false
+}
+
+// RemoveRange removes all the nodes of which the key is between `low` and `high` within one RWMutex.Lock,
+// and returns the count of removed nodes.
+// The `low` and `high` are included in the range if `inclusive` is true, or else excluded.
+func (tree *RedBlackTree) RemoveRange(low, high interface{}, inclusive bool) int {
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+	entries := tree.doBetween(low, high, inclusive)
+	for _, entry := range entries {
+		tree.doRemove(entry.Key)
+	}
+	return len(entries)
+}
+
 // IsEmpty returns true if tree does not contain any nodes.
 func (tree *RedBlackTree) IsEmpty() bool {
 	return tree.Size() == 0
@@ -336,6 +574,12 @@ func (tree *RedBlackTree) Size() int {
 	return tree.size
 }
 
+// DistinctSize returns the count of distinct keys in the tree, which is the same as Size,
+// as the keys of RedBlackTree are unique. See RedBlackTreeMulti.DistinctSize.
+func (tree *RedBlackTree) DistinctSize() int {
+	return tree.Size()
+}
+
 // Keys returns all keys in asc order.
 func (tree *RedBlackTree) Keys() []interface{} {
 	var (
@@ -364,6 +608,24 @@ func (tree *RedBlackTree) Values() []interface{} {
 	return values
 }
 
+// Flatten returns all key-value items in asc order based on the key as a slice of Entry,
+// which is produced by a single in-order traversal within RWMutex.RLock.
+func (tree *RedBlackTree) Flatten() []Entry {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+	return tree.doFlatten()
+}
+
+// doFlatten returns all key-value items in asc order based on the key as a slice of Entry without mutex.
+func (tree *RedBlackTree) doFlatten() []Entry {
+	entries := make([]Entry, 0, tree.size)
+	tree.doIteratorAsc(tree.leftNode(), func(key, value interface{}) bool {
+		entries = append(entries, Entry{Key: key, Value: value})
+		return true
+	})
+	return entries
+}
+
 // Map returns all key-value items as map.
 func (tree *RedBlackTree) Map() map[interface{}]interface{} {
 	m := make(map[interface{}]interface{}, tree.Size())
@@ -389,6 +651,9 @@ func (tree *RedBlackTree) Left() *RedBlackTreeNode {
 	tree.mu.RLock()
 	defer tree.mu.RUnlock()
 	node := tree.leftNode()
+	if node == nil {
+		return nil
+	}
 	if tree.mu.IsSafe() {
 		return &RedBlackTreeNode{
 			Key:   node.Key,
@@ -403,6 +668,9 @@ func (tree *RedBlackTree) Right() *RedBlackTreeNode {
 	tree.mu.RLock()
 	defer tree.mu.RUnlock()
 	node := tree.rightNode()
+	if node == nil {
+		return nil
+	}
 	if tree.mu.IsSafe() {
 		return &RedBlackTreeNode{
 			Key:   node.Key,
@@ -443,6 +711,11 @@ func (tree *RedBlackTree) rightNode() *RedBlackTreeNode {
 func (tree *RedBlackTree) Floor(key interface{}) (floor *RedBlackTreeNode, found bool) {
 	tree.mu.RLock()
 	defer tree.mu.RUnlock()
+	return tree.doFloor(key)
+}
+
+// doFloor finds floor node of the input key without mutex.
+func (tree *RedBlackTree) doFloor(key interface{}) (floor *RedBlackTreeNode, found bool) {
 	n := tree.root
 	for n != nil {
 		compare := tree.getComparator()(key, n.Key)
@@ -471,6 +744,11 @@ func (tree *RedBlackTree) Floor(key interface{}) (floor *RedBlackTreeNode, found
 func (tree *RedBlackTree) Ceiling(key interface{}) (ceiling *RedBlackTreeNode, found bool) {
 	tree.mu.RLock()
 	defer tree.mu.RUnlock()
+	return tree.doCeiling(key)
+}
+
+// doCeiling finds ceiling node of the input key without mutex.
+func (tree *RedBlackTree) doCeiling(key interface{}) (ceiling *RedBlackTreeNode, found bool) {
 	n := tree.root
 	for n != nil {
 		compare := tree.getComparator()(key, n.Key)
@@ -490,6 +768,203 @@ func (tree *RedBlackTree) Ceiling(key interface{}) (ceiling *RedBlackTreeNode, f
 	return nil, false
 }
 
+// Predecessor returns the key-value item of which the key is the largest one that is smaller than `key`.
+// It differs with Floor that the item of `key` itself is excluded, and `key` does not need to be in the tree.
+// The returned `found` is false if there is no such item.
+func (tree *RedBlackTree) Predecessor(key interface{}) (k, v interface{}, found bool) {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+	var (
+		comparator  = tree.getComparator()
+		predecessor *RedBlackTreeNode
+	)
+	for n := tree.root; n != nil; {
+		if comparator(key, n.Key) > 0 {
+			predecessor = n
+			n = n.right
+		} else {
+			n = n.left
+		}
+	}
+	if predecessor == nil {
+		return nil, nil, false
+	}
+	return predecessor.Key, predecessor.Value, true
+}
+
+// Successor returns the key-value item of which the key is the smallest one that is larger than `key`.
+// It differs with Ceiling that the item of `key` itself is excluded, and `key` does not need to be in the tree.
+// The returned `found` is false if there is no such item.
+func (tree *RedBlackTree) Successor(key interface{}) (k, v interface{}, found bool) {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+	var (
+		comparator = tree.getComparator()
+		successor  *RedBlackTreeNode
+	)
+	for n := tree.root; n != nil; {
+		if comparator(key, n.Key) < 0 {
+			successor = n
+			n = n.left
+		} else {
+			n = n.right
+		}
+	}
+	if successor == nil {
+		return nil, nil, false
+	}
+	return successor.Key, successor.Value, true
+}
+
+// Nearest returns the key-value item of which the key is the nearest to given `key`,
+// which is either the floor or ceiling node of `key` that has the smaller distance to `key`
+// measured by the callback function `distance`. The floor node is returned if both of them
+// have the same distance.
+// The returned `found` is false if the tree is empty.
+func (tree *RedBlackTree) Nearest(
+	key interface{}, distance func(a, b interface{}) int,
+) (nearestKey, nearestValue interface{}, found bool) {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+	var (
+		floor, floorFound     = tree.doFloor(key)
+		ceiling, ceilingFound = tree.doCeiling(key)
+		nearest               *RedBlackTreeNode
+	)
+	switch {
+	case floorFound && ceilingFound:
+		nearest = floor
+		if distance(key, ceiling.Key) < distance(key, floor.Key) {
+			nearest = ceiling
+		}
+	case floorFound:
+		nearest = floor
+	case ceilingFound:
+		nearest = ceiling
+	default:
+		return nil, nil, false
+	}
+	return nearest.Key, nearest.Value, true
+}
+
+// Between returns the key-value items of which the key is between `low` and `high` in ascending order.
+// The `low` and `high` are included in the range if `inclusive` is true, or else excluded.
+// It returns an empty slice if no key is in the range.
+func (tree *RedBlackTree) Between(low, high interface{}, inclusive bool) []Entry {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+	return tree.doBetween(low, high, inclusive)
+}
+
+// doBetween returns the key-value items of which the key is between `low` and `high` without mutex.
+func (tree *RedBlackTree) doBetween(low, high interface{}, inclusive bool) []Entry {
+	var (
+		entries    = make([]Entry, 0)
+		comparator = tree.getComparator()
+		node, _    = tree.doCeiling(low)
+	)
+	tree.doIteratorAsc(node, func(key, value interface{}) bool {
+		if !inclusive && comparator(key, low) == 0 {
+			return true
+		}
+		compare := comparator(key, high)
+		if compare > 0 || (!inclusive && compare == 0) {
+			return false
+		}
+		entries = append(entries, Entry{Key: key, Value: value})
+		return true
+	})
+	return entries
+}
+
+// WithinFloat returns the key-value items of which the numeric key is within [target-tolerance, target+tolerance]
+// in ascending order, which is used for the approximate lookups like the nearby timestamps.
+// The range is searched by Between with the float64 bounds, and the keys are checked by converting to float64,
+// so the comparator of the tree should compare the keys numerically, like ComparatorInt and ComparatorFloat64.
+// It returns an empty slice if `tolerance` is negative or NaN.
+func (tree *RedBlackTree) WithinFloat(target, tolerance float64) []Entry {
+	if !(tolerance >= 0) {
+		return make([]Entry, 0)
+	}
+	var (
+		low     = target - tolerance
+		high    = target + tolerance
+		entries = tree.Between(low, high, true)
+		result  = entries[:0]
+	)
+	// The comparator might convert the bounds to the type of keys, like ComparatorInt truncating them to int,
+	// so the keys out of the range are filtered out.
+	for _, entry := range entries {
+		if key := gconv.Float64(entry.Key); key >= low && key <= high {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+// Select returns the key-value item of the `k`-th smallest key in the tree, which is 0-indexed.
+// The returned `found` is false if `k` is out of range [0, Size()).
+func (tree *RedBlackTree) Select(k int) (key, value interface{}, found bool) {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+	if k < 0 || k >= tree.size {
+		return nil, nil, false
+	}
+	n := tree.root
+	for n != nil {
+		leftSize := n.left.subtreeSize()
+		switch {
+		case k < leftSize:
+			n = n.left
+		case k == leftSize:
+			return n.Key, n.Value, true
+		default:
+			k -= leftSize + 1
+			n = n.right
+		}
+	}
+	return nil, nil, false
+}
+
+// Rank returns the number of keys in the tree that are strictly less than the given `key`.
+// The `key` does not need to exist in the tree.
+func (tree *RedBlackTree) Rank(key interface{}) int {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+	return tree.doRank(key, false)
+}
+
+// CountRange returns the number of keys in the tree that are between `low` and `high` in O(log n)
+// using the subtree sizes, without iterating the keys in the range.
+// The `low` and `high` are included in the range if `inclusive` is true, or else excluded.
+func (tree *RedBlackTree) CountRange(low, high interface{}, inclusive bool) int {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+	if count := tree.doRank(high, inclusive) - tree.doRank(low, !inclusive); count > 0 {
+		return count
+	}
+	return 0
+}
+
+// doRank returns the number of keys in the tree that are less than the given `key` without mutex.
+// The keys equal to `key` are also counted if `orEqual` is true.
+func (tree *RedBlackTree) doRank(key interface{}, orEqual bool) int {
+	var (
+		rank = 0
+		n    = tree.root
+	)
+	for n != nil {
+		compare := tree.getComparator()(key, n.Key)
+		if compare < 0 || (compare == 0 && !orEqual) {
+			n = n.left
+		} else {
+			rank += n.left.subtreeSize() + 1
+			n = n.right
+		}
+	}
+	return rank
+}
+
 // Iterator is alias of IteratorAsc.
 func (tree *RedBlackTree) Iterator(f func(key, value interface{}) bool) {
 	tree.IteratorAsc(f)
@@ -508,6 +983,49 @@ func (tree *RedBlackTree) IteratorAsc(f func(key, value interface{}) bool) {
 	tree.doIteratorAsc(tree.leftNode(), f)
 }
 
+// IteratorAscSnapshot iterates a snapshot of the tree readonly in ascending order with given callback function `f`.
+// If `f` returns true, then it continues iterating; or false to stop.
+//
+// IteratorAscSnapshot differs with IteratorAsc function is that it copies the keys and values
+// within a brief RWMutex.RLock and calls `f` after the lock is released, so that slow `f`
+// does not block the writers, and `f` can also change the tree. Note that the snapshot might be
+// stale if the tree is changed concurrently during iterating.
+func (tree *RedBlackTree) IteratorAscSnapshot(f func(key, value interface{}) bool) {
+	tree.mu.RLock()
+	entries := tree.doFlatten()
+	tree.mu.RUnlock()
+	for _, entry := range entries {
+		if !f(entry.Key, entry.Value) {
+			break
+		}
+	}
+}
+
+// RangePage returns at most `limit` entries in ascending order starting from the ceiling of `low`
+// within one RWMutex.RLock, which is used for the cursor pagination of the tree.
+// The returned `hasMore` is true if there are more entries after the returned ones, and `nextKey` is the key
+// of the next entry, which can be used as the `low` for the next page. The `nextKey` is nil if `hasMore` is false.
+func (tree *RedBlackTree) RangePage(low interface{}, limit int) (entries []Entry, nextKey interface{}, hasMore bool) {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+	node, found := tree.doCeiling(low)
+	if !found {
+		return nil, nil, false
+	}
+	if limit < 1 {
+		return nil, node.Key, true
+	}
+	tree.doIteratorAsc(node, func(key, value interface{}) bool {
+		if len(entries) == limit {
+			nextKey, hasMore = key, true
+			return false
+		}
+		entries = append(entries, Entry{Key: key, Value: value})
+		return true
+	})
+	return
+}
+
 // IteratorAscFrom iterates the tree readonly in ascending order with given callback function `f`.
 // The parameter `key` specifies the start entry for iterating. The `match` specifies whether
 // starting iterating if the `key` is fully matched, or else using index searching iterating.
@@ -602,12 +1120,45 @@ loop:
 	}
 }
 
+// IteratorLevel iterates the tree readonly in level order (breadth-first) with given callback function `f`,
+// which is used for inspecting the shape of the tree. The parameter `level` of `f` is the depth of the node,
+// which is 0 for the root node. The nodes of the same level are iterated in ascending order.
+// If `f` returns true, then it continues iterating; or false to stop.
+func (tree *RedBlackTree) IteratorLevel(f func(level int, key, value interface{}) bool) {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+	if tree.root == nil {
+		return
+	}
+	var (
+		level = 0
+		queue = []*RedBlackTreeNode{tree.root}
+	)
+	for len(queue) > 0 {
+		var next []*RedBlackTreeNode
+		for _, node := range queue {
+			if !f(level, node.Key, node.Value) {
+				return
+			}
+			if node.left != nil {
+				next = append(next, node.left)
+			}
+			if node.right != nil {
+				next = append(next, node.right)
+			}
+		}
+		queue = next
+		level++
+	}
+}
+
 // Clear removes all nodes from the tree.
 func (tree *RedBlackTree) Clear() {
 	tree.mu.Lock()
 	defer tree.mu.Unlock()
 	tree.root = nil
 	tree.size = 0
+	tree.shared = false
 }
 
 // Replace the data of the tree with given `data`.
@@ -616,11 +1167,172 @@ func (tree *RedBlackTree) Replace(data map[interface{}]interface{}) {
 	defer tree.mu.Unlock()
 	tree.root = nil
 	tree.size = 0
+	tree.shared = false
 	for k, v := range data {
 		tree.doSet(k, v)
 	}
 }
 
+// BuildFromSorted replaces the data of the tree with given `keys` and `values`, which builds a
+// height-balanced tree bottom-up in O(n) without rotations. The `keys` should be sorted in ascending
+// order by the comparator of the tree, and `values` are the values of `keys` in the same order.
+// If there are adjacent duplicated keys, the latter one is kept.
+//
+// It returns an error and leaves the tree unchanged if the lengths of `keys` and `values` are not
+// the same, or `keys` are not sorted.
+func (tree *RedBlackTree) BuildFromSorted(keys, values []interface{}) error {
+	if len(keys) != len(values) {
+		return gerror.NewCodef(
+			gcode.CodeInvalidParameter,
+			`length of keys %d and values %d are not the same`,
+			len(keys), len(values),
+		)
+	}
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+	var (
+		comparator   = tree.getComparator()
+		sortedKeys   = make([]interface{}, 0, len(keys))
+		sortedValues = make([]interface{}, 0, len(values))
+	)
+	for i, key := range keys {
+		if n := len(sortedKeys); n > 0 {
+			compare := comparator(sortedKeys[n-1], key)
+			if compare > 0 {
+				return gerror.NewCodef(gcode.CodeInvalidParameter, `keys are not sorted at index %d`, i)
+			}
+			if compare == 0 {
+				sortedValues[n-1] = values[i]
+				continue
+			}
+		}
+		sortedKeys = append(sortedKeys, key)
+		sortedValues = append(sortedValues, values[i])
+	}
+	tree.doBuildFromSorted(sortedKeys, sortedValues)
+	return nil
+}
+
+// Dump writes all the key-value items of the tree to `w` in ascending order in binary form,
+// which can be read by Load. The items are encoded by encoding/gob after the count of items.
+// Note that the keys and values should be gob-encodable, and their concrete types other than
+// the basic types should be registered by gob.Register.
+func (tree *RedBlackTree) Dump(w io.Writer) error {
+	tree.mu.RLock()
+	entries := tree.doFlatten()
+	tree.mu.RUnlock()
+	encoder := gob.NewEncoder(w)
+	if err := encoder.Encode(len(entries)); err != nil {
+		return gerror.Wrap(err, `gob encoding tree size failed`)
+	}
+	for _, entry := range entries {
+		if err := encoder.Encode(gobEntry{Key: entry.Key, Value: entry.Value}); err != nil {
+			return gerror.Wrapf(err, `gob encoding tree item of key "%v" failed`, entry.Key)
+		}
+	}
+	return nil
+}
+
+// Load reads the key-value items written by Dump from `r`, and replaces the data of the tree with them.
+// The tree is built bottom-up in O(n) like BuildFromSorted, so the comparator of the tree should order the keys
+// in the same way as the tree dumping them, or else it returns an error and the tree is not changed.
+func (tree *RedBlackTree) Load(r io.Reader) error {
+	tree.mu.RLock()
+	hasComparator := tree.comparator != nil
+	tree.mu.RUnlock()
+	if !hasComparator {
+		return gerror.NewCode(gcode.CodeInvalidOperation, `comparator is missing for tree`)
+	}
+	var (
+		size    int
+		decoder = gob.NewDecoder(r)
+	)
+	if err := decoder.Decode(&size); err != nil {
+		return gerror.Wrap(err, `gob decoding tree size failed`)
+	}
+	if size < 0 {
+		return gerror.NewCodef(gcode.CodeInvalidParameter, `invalid tree size %d`, size)
+	}
+	var (
+		keys   []interface{}
+		values []interface{}
+	)
+	for i := 0; i < size; i++ {
+		var entry gobEntry
+		if err := decoder.Decode(&entry); err != nil {
+			return gerror.Wrapf(err, `gob decoding tree item at index %d failed`, i)
+		}
+		keys = append(keys, entry.Key)
+		values = append(values, entry.Value)
+	}
+	return tree.BuildFromSorted(keys, values)
+}
+
+// gobEntry is a key-value item of the tree encoded by Dump.
+type gobEntry struct {
+	Key   interface{}
+	Value interface{}
+}
+
+// Compact rebuilds the tree into a perfectly balanced one with fresh nodes within one RWMutex.Lock,
+// which keeps all the key-value items and the comparator. It drops the old nodes for the GC to reclaim,
+// which is useful for long-lived trees that are heavily changed.
+func (tree *RedBlackTree) Compact() {
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+	var (
+		keys   = make([]interface{}, 0, tree.size)
+		values = make([]interface{}, 0, tree.size)
+	)
+	tree.doIteratorAsc(tree.leftNode(), func(key, value interface{}) bool {
+		keys = append(keys, key)
+		values = append(values, value)
+		return true
+	})
+	tree.doBuildFromSorted(keys, values)
+}
+
+// doBuildFromSorted replaces the data of the tree with strictly ascending `keys` and their `values`
+// without mutex.
+func (tree *RedBlackTree) doBuildFromSorted(keys, values []interface{}) {
+	// All the nodes at the deepest level are colored red and the others are black,
+	// so that every path from the root to the leaves has the same count of black nodes.
+	redDepth := bits.Len(uint(len(keys))) - 1
+	tree.root = tree.buildFromSorted(keys, values, 0, redDepth)
+	if tree.root != nil {
+		tree.root.color = black
+	}
+	tree.size = len(keys)
+	tree.shared = false
+}
+
+// buildFromSorted builds subtree from sorted `keys` and `values` recursively,
+// in which the nodes at `redDepth` are colored red.
+func (tree *RedBlackTree) buildFromSorted(keys, values []interface{}, depth, redDepth int) *RedBlackTreeNode {
+	if len(keys) == 0 {
+		return nil
+	}
+	mid := (len(keys) - 1) / 2
+	node := &RedBlackTreeNode{
+		Key:   keys[mid],
+		Value: values[mid],
+		color: black,
+		size:  len(keys),
+	}
+	if depth == redDepth {
+		node.color = red
+	}
+	node.left = tree.buildFromSorted(keys[:mid], values[:mid], depth+1, redDepth)
+	node.right = tree.buildFromSorted(keys[mid+1:], values[mid+1:], depth+1, redDepth)
+	if node.left != nil {
+		node.left.parent = node
+	}
+	if node.right != nil {
+		node.right.parent = node
+	}
+	return node
+}
+
 // String returns a string representation of container.
 func (tree *RedBlackTree) String() string {
 	if tree == nil {
@@ -637,7 +1349,12 @@ func (tree *RedBlackTree) String() string {
 
 // Print prints the tree to stdout.
 func (tree *RedBlackTree) Print() {
-	fmt.Println(tree.String())
+	tree.Output(os.Stdout)
+}
+
+// Output writes the string representation of the tree to writer `w`, which is the same as Print.
+func (tree *RedBlackTree) Output(w io.Writer) {
+	_, _ = fmt.Fprintln(w, tree.String())
 }
 
 // Search searches the tree with given `key`.
@@ -652,26 +1369,124 @@ func (tree *RedBlackTree) Search(key interface{}) (value interface{}, found bool
 	return nil, false
 }
 
-// Flip exchanges key-value of the tree to value-key.
-// Note that you should guarantee the value is the same type as key,
-// or else the comparator would panic.
+// Flip exchanges key-value of the tree to value-key, which rebuilds the tree ordering
+// the former values. If there are duplicated values, the greatest former key of them is kept.
 //
-// If the type of value is different with key, you pass the new `comparator`.
-func (tree *RedBlackTree) Flip(comparator ...func(v1, v2 interface{}) int) {
-	t := (*RedBlackTree)(nil)
-	if len(comparator) > 0 {
-		t = NewRedBlackTree(comparator[0], tree.mu.IsSafe())
-	} else {
-		t = NewRedBlackTree(tree.comparator, tree.mu.IsSafe())
-	}
-	tree.IteratorAsc(func(key, value interface{}) bool {
-		t.doSet(value, key)
+// The former values are ordered by the comparator of the tree in default, which requires the
+// value to be the same type as key. If the type of value is different with key, you pass the new
+// `comparator`, which replaces the comparator of the tree after flipping.
+//
+// It returns an error and leaves the tree unchanged if any value cannot be ordered by the comparator.
+func (tree *RedBlackTree) Flip(comparator ...func(v1, v2 interface{}) int) (err error) {
+	tree.mu.Lock()
+	defer tree.mu.Unlock()
+	t := NewRedBlackTree(tree.getComparator())
+	if len(comparator) > 0 && comparator[0] != nil {
+		t.comparator = comparator[0]
+	}
+	var value interface{}
+	defer func() {
+		if exception := recover(); exception != nil {
+			err = gerror.NewCodef(
+				gcode.CodeInvalidParameter,
+				`value "%v" cannot be ordered by the comparator: %+v`,
+				value, exception,
+			)
+		}
+	}()
+	tree.doIteratorAsc(tree.leftNode(), func(k, v interface{}) bool {
+		value = v
+		t.doSet(v, k)
 		return true
 	})
-	tree.mu.Lock()
 	tree.root = t.root
 	tree.size = t.size
-	tree.mu.Unlock()
+	tree.shared = false
+	tree.comparator = t.comparator
+	return nil
+}
+
+// Check verifies the invariants of the red-black tree, which is useful for testing and debugging
+// as a buggy comparator corrupts the tree silently. It checks that:
+// the root is black, no red node has a red child, every path from the root to the leaves
+// has the same count of black nodes, the keys are in ascending order by the comparator in in-order
+// traversal, and the parent links and subtree sizes of the nodes are correct.
+//
+// It returns nil if the tree is valid, or else an error describing the first violated invariant.
+func (tree *RedBlackTree) Check() error {
+	tree.mu.RLock()
+	defer tree.mu.RUnlock()
+	if tree.root == nil {
+		if tree.size != 0 {
+			return gerror.NewCodef(gcode.CodeInternalError, `empty tree has size %d`, tree.size)
+		}
+		return nil
+	}
+	if tree.root.color != black {
+		return gerror.NewCodef(gcode.CodeInternalError, `root "%v" is not black`, tree.root.Key)
+	}
+	if tree.root.parent != nil {
+		return gerror.NewCodef(gcode.CodeInternalError, `root "%v" has parent`, tree.root.Key)
+	}
+	if tree.root.size != tree.size {
+		return gerror.NewCodef(
+			gcode.CodeInternalError, `root size %d differs from tree size %d`, tree.root.size, tree.size,
+		)
+	}
+	var prev *RedBlackTreeNode
+	_, err := tree.doCheck(tree.root, &prev)
+	return err
+}
+
+// doCheck checks the invariants of the subtree of `node` without mutex, and returns its black height.
+// The parameter `prev` is the previous node in in-order traversal, which is updated after checking.
+func (tree *RedBlackTree) doCheck(node *RedBlackTreeNode, prev **RedBlackTreeNode) (blackHeight int, err error) {
+	if node == nil {
+		return 1, nil
+	}
+	for _, child := range []*RedBlackTreeNode{node.left, node.right} {
+		if child == nil {
+			continue
+		}
+		if child.parent != node {
+			return 0, gerror.NewCodef(gcode.CodeInternalError, `node "%v" has wrong parent`, child.Key)
+		}
+		if node.color == red && child.color == red {
+			return 0, gerror.NewCodef(
+				gcode.CodeInternalError, `red node "%v" has red child "%v"`, node.Key, child.Key,
+			)
+		}
+	}
+	leftHeight, err := tree.doCheck(node.left, prev)
+	if err != nil {
+		return 0, err
+	}
+	if *prev != nil && tree.getComparator()((*prev).Key, node.Key) >= 0 {
+		return 0, gerror.NewCodef(
+			gcode.CodeInternalError, `keys "%v" and "%v" are not in ascending order`, (*prev).Key, node.Key,
+		)
+	}
+	*prev = node
+	rightHeight, err := tree.doCheck(node.right, prev)
+	if err != nil {
+		return 0, err
+	}
+	if size := node.left.subtreeSize() + node.right.subtreeSize() + 1; node.size != size {
+		return 0, gerror.NewCodef(
+			gcode.CodeInternalError, `node "%v" has size %d but expected %d`, node.Key, node.size, size,
+		)
+	}
+	if leftHeight != rightHeight {
+		return 0, gerror.NewCodef(
+			gcode.CodeInternalError,
+			`node "%v" has different black heights %d and %d of subtrees`,
+			node.Key, leftHeight, rightHeight,
+		)
+	}
+	if node.color == black {
+		leftHeight++
+	}
+	return leftHeight, nil
 }
 
 func (tree *RedBlackTree) output(node *RedBlackTreeNode, prefix string, isTail bool, str *string) {
@@ -753,6 +1568,8 @@ func (tree *RedBlackTree) rotateLeft(node *RedBlackTreeNode) {
 	}
 	right.left = node
 	node.parent = right
+	right.size = node.size
+	node.size = node.left.subtreeSize() + node.right.subtreeSize() + 1
 }
 
 func (tree *RedBlackTree) rotateRight(node *RedBlackTreeNode) {
@@ -764,6 +1581,8 @@ func (tree *RedBlackTree) rotateRight(node *RedBlackTreeNode) {
 	}
 	left.right = node
 	node.parent = left
+	left.size = node.size
+	node.size = node.left.subtreeSize() + node.right.subtreeSize() + 1
 }
 
 func (tree *RedBlackTree) replaceNode(old *RedBlackTreeNode, new *RedBlackTreeNode) {
@@ -836,11 +1655,20 @@ func (node *RedBlackTreeNode) maximumNode() *RedBlackTreeNode {
 		return nil
 	}
 	for node.right != nil {
-		return node.right
+		node = node.right
 	}
 	return node
 }
 
+// subtreeSize returns the number of nodes in the subtree rooted at `node`,
+// which is 0 if `node` is nil.
+func (node *RedBlackTreeNode) subtreeSize() int {
+	if node == nil {
+		return 0
+	}
+	return node.size
+}
+
 func (tree *RedBlackTree) deleteCase1(node *RedBlackTreeNode) {
 	if node.parent == nil {
 		return
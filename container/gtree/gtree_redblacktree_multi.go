@@ -0,0 +1,181 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gtree
+
+// RedBlackTreeMulti is a red-black tree in multimap mode, which allows duplicated keys.
+// Setting an existing key appends the value to the values of the key instead of overwriting it,
+// and the values of the same key are kept in insertion order.
+//
+// It wraps RedBlackTree, in which each node holds all the values of its key.
+type RedBlackTreeMulti struct {
+	tree *RedBlackTree
+	size int // size is the count of all the values.
+}
+
+// NewRedBlackTreeMulti instantiates a red-black tree in multimap mode with the custom key comparator.
+// The parameter `safe` is used to specify whether using tree in concurrent-safety,
+// which is false in default.
+func NewRedBlackTreeMulti(comparator func(v1, v2 interface{}) int, safe ...bool) *RedBlackTreeMulti {
+	return &RedBlackTreeMulti{
+		tree: NewRedBlackTree(comparator, safe...),
+	}
+}
+
+// Set appends `value` to the values of `key` in the tree.
+func (tree *RedBlackTreeMulti) Set(key interface{}, value interface{}) {
+	tree.tree.mu.Lock()
+	defer tree.tree.mu.Unlock()
+	if node, found := tree.tree.doSearch(key); found {
+		node.Value = append(node.Value.([]interface{}), value)
+	} else {
+		tree.tree.doSet(key, []interface{}{value})
+	}
+	tree.size++
+}
+
+// Search searches the tree with given `key` and returns its first value in insertion order.
+// Second return parameter `found` is true if key was found, otherwise false.
+func (tree *RedBlackTreeMulti) Search(key interface{}) (value interface{}, found bool) {
+	tree.tree.mu.RLock()
+	defer tree.tree.mu.RUnlock()
+	if node, found := tree.tree.doSearch(key); found {
+		return node.Value.([]interface{})[0], true
+	}
+	return nil, false
+}
+
+// SearchAll returns all the values of `key` in insertion order, or nil if `key` is not found.
+func (tree *RedBlackTreeMulti) SearchAll(key interface{}) []interface{} {
+	tree.tree.mu.RLock()
+	defer tree.tree.mu.RUnlock()
+	if node, found := tree.tree.doSearch(key); found {
+		values := node.Value.([]interface{})
+		return append(make([]interface{}, 0, len(values)), values...)
+	}
+	return nil
+}
+
+// Contains checks whether `key` exists in the tree.
+func (tree *RedBlackTreeMulti) Contains(key interface{}) bool {
+	return tree.tree.Contains(key)
+}
+
+// Count returns the count of values of `key`.
+func (tree *RedBlackTreeMulti) Count(key interface{}) int {
+	tree.tree.mu.RLock()
+	defer tree.tree.mu.RUnlock()
+	if node, found := tree.tree.doSearch(key); found {
+		return len(node.Value.([]interface{}))
+	}
+	return 0
+}
+
+// Remove removes the first value of `key` in insertion order, and returns the removed value.
+// The key is removed from the tree if it has no value anymore.
+// Second return parameter `found` is true if key was found, otherwise false.
+func (tree *RedBlackTreeMulti) Remove(key interface{}) (value interface{}, found bool) {
+	tree.tree.mu.Lock()
+	defer tree.tree.mu.Unlock()
+	node, found := tree.tree.doSearch(key)
+	if !found {
+		return nil, false
+	}
+	values := node.Value.([]interface{})
+	value = values[0]
+	if len(values) == 1 {
+		tree.tree.doRemove(key)
+	} else {
+		values[0] = nil
+		node.Value = values[1:]
+	}
+	tree.size--
+	return value, true
+}
+
+// RemoveAll removes `key` with all its values from the tree, and returns the removed values
+// in insertion order, or nil if `key` is not found.
+func (tree *RedBlackTreeMulti) RemoveAll(key interface{}) []interface{} {
+	tree.tree.mu.Lock()
+	defer tree.tree.mu.Unlock()
+	if _, found := tree.tree.doSearch(key); !found {
+		return nil
+	}
+	values := tree.tree.doRemove(key).([]interface{})
+	tree.size -= len(values)
+	return values
+}
+
+// Size returns the count of all the values in the tree, including the values of duplicated keys.
+func (tree *RedBlackTreeMulti) Size() int {
+	tree.tree.mu.RLock()
+	defer tree.tree.mu.RUnlock()
+	return tree.size
+}
+
+// DistinctSize returns the count of distinct keys in the tree.
+func (tree *RedBlackTreeMulti) DistinctSize() int {
+	return tree.tree.Size()
+}
+
+// IsEmpty returns true if tree does not contain any nodes.
+func (tree *RedBlackTreeMulti) IsEmpty() bool {
+	return tree.Size() == 0
+}
+
+// Keys returns all distinct keys in asc order.
+func (tree *RedBlackTreeMulti) Keys() []interface{} {
+	return tree.tree.Keys()
+}
+
+// Values returns all values in asc order based on the key,
+// in which the values of the same key are in insertion order.
+func (tree *RedBlackTreeMulti) Values() []interface{} {
+	values := make([]interface{}, 0, tree.Size())
+	tree.IteratorAsc(func(key, value interface{}) bool {
+		values = append(values, value)
+		return true
+	})
+	return values
+}
+
+// IteratorAsc iterates the tree readonly in ascending order with given callback function `f`,
+// in which the values of the same key are visited in insertion order.
+// If `f` returns true, then it continues iterating; or false to stop.
+func (tree *RedBlackTreeMulti) IteratorAsc(f func(key, value interface{}) bool) {
+	tree.tree.IteratorAsc(func(key, values interface{}) bool {
+		for _, value := range values.([]interface{}) {
+			if !f(key, value) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// IteratorDesc iterates the tree readonly in descending order with given callback function `f`,
+// in which the values of the same key are visited in reverse insertion order.
+// If `f` returns true, then it continues iterating; or false to stop.
+func (tree *RedBlackTreeMulti) IteratorDesc(f func(key, value interface{}) bool) {
+	tree.tree.IteratorDesc(func(key, values interface{}) bool {
+		array := values.([]interface{})
+		for i := len(array) - 1; i >= 0; i-- {
+			if !f(key, array[i]) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// Clear removes all nodes from the tree.
+func (tree *RedBlackTreeMulti) Clear() {
+	tree.tree.mu.Lock()
+	defer tree.tree.mu.Unlock()
+	tree.tree.root = nil
+	tree.tree.size = 0
+	tree.size = 0
+}
@@ -8,11 +8,15 @@ package gtree_test
 
 import (
 	"fmt"
+	"math"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/gogf/gf/v2/container/gtree"
 	"github.com/gogf/gf/v2/container/gvar"
 	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/grand"
 	"github.com/gogf/gf/v2/util/gutil"
 )
 
@@ -291,3 +295,60 @@ func Test_AVLTree_Remove(t *testing.T) {
 		}
 	})
 }
+
+func Test_AVLTree_Balance(t *testing.T) {
+	// heightOfString calculates the real height of the tree from its string representation,
+	// in which each level of depth is indented with four characters.
+	heightOfString := func(s string) int {
+		height := 0
+		for _, line := range strings.Split(strings.TrimRight(s, "\n"), "\n") {
+			if line == "" {
+				continue
+			}
+			depth := 0
+			for _, prefix := range []string{"└── ", "┌── "} {
+				if pos := strings.Index(line, prefix); pos >= 0 {
+					depth = len([]rune(line[:pos]))/4 + 1
+				}
+			}
+			if depth > height {
+				height = depth
+			}
+		}
+		return height
+	}
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			tree      = gtree.NewAVLTree(gutil.ComparatorInt)
+			reference = make(map[int]int)
+		)
+		t.Assert(tree.Height(), 0)
+		for i := 0; i < 5000; i++ {
+			key := grand.N(0, 1000)
+			if grand.N(0, 3) == 0 {
+				tree.Remove(key)
+				delete(reference, key)
+			} else {
+				tree.Set(key, key)
+				reference[key] = key
+			}
+			if i%250 != 0 {
+				continue
+			}
+			var (
+				size   = tree.Size()
+				height = tree.Height()
+				keys   = make([]int, 0, len(reference))
+			)
+			for key := range reference {
+				keys = append(keys, key)
+			}
+			sort.Ints(keys)
+			t.Assert(size, len(keys))
+			t.Assert(tree.Keys(), keys)
+			t.Assert(height, heightOfString(tree.String()))
+			// The height of an AVL tree with n nodes is strictly less than 1.4405*log2(n+2)-0.3277.
+			t.Assert(float64(height) < 1.4405*math.Log2(float64(size+2))-0.3277, true)
+		}
+	})
+}
@@ -8,11 +8,13 @@ package gtree_test
 
 import (
 	"fmt"
+	"sort"
 	"testing"
 
 	"github.com/gogf/gf/v2/container/gtree"
 	"github.com/gogf/gf/v2/container/gvar"
 	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/grand"
 	"github.com/gogf/gf/v2/util/gutil"
 )
 
@@ -240,3 +242,58 @@ func Test_BTree_Remove(t *testing.T) {
 		}
 	})
 }
+
+func Test_BTree_Randomized(t *testing.T) {
+	for _, degree := range []int{3, 4, 8, 32} {
+		gtest.C(t, func(t *gtest.T) {
+			var (
+				tree      = gtree.NewBTree(degree, gutil.ComparatorInt)
+				reference = make(map[int]int)
+			)
+			for i := 0; i < 20000; i++ {
+				key := grand.N(0, 5000)
+				if grand.N(0, 3) == 0 {
+					v, ok := reference[key]
+					if ok {
+						t.Assert(tree.Remove(key), v)
+					} else {
+						t.Assert(tree.Remove(key), nil)
+					}
+					delete(reference, key)
+				} else {
+					tree.Set(key, i)
+					reference[key] = i
+				}
+			}
+			var (
+				keys   = make([]int, 0, len(reference))
+				values = make([]int, 0, len(reference))
+			)
+			for key := range reference {
+				keys = append(keys, key)
+			}
+			sort.Ints(keys)
+			for _, key := range keys {
+				values = append(values, reference[key])
+			}
+			t.Assert(tree.Size(), len(keys))
+			t.Assert(tree.Keys(), keys)
+			t.Assert(tree.Values(), values)
+			for _, key := range keys {
+				v, found := tree.Search(key)
+				t.Assert(found, true)
+				t.Assert(v, reference[key])
+			}
+			_, found := tree.Search(-1)
+			t.Assert(found, false)
+
+			index := 0
+			tree.IteratorAsc(func(key, value interface{}) bool {
+				t.Assert(key, keys[index])
+				index++
+				return true
+			})
+			t.Assert(index, len(keys))
+		})
+	}
+}
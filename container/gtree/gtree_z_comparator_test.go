@@ -0,0 +1,71 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gtree_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/container/gtree"
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+func Test_Comparator(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		t.Assert(gtree.ComparatorInt(1, 2), -1)
+		t.Assert(gtree.ComparatorInt(2, 1), 1)
+		t.Assert(gtree.ComparatorInt(2, 2), 0)
+		t.Assert(gtree.ComparatorInt(math.MaxInt64, math.MinInt64), 1)
+		t.Assert(gtree.ComparatorInt(math.MinInt64, math.MaxInt64), -1)
+
+		t.Assert(gtree.ComparatorInt64(int64(math.MaxInt64), int64(-1)), 1)
+		t.Assert(gtree.ComparatorInt64(int64(math.MinInt64), int64(1)), -1)
+		t.Assert(gtree.ComparatorInt64(int64(3), int64(3)), 0)
+
+		t.Assert(gtree.ComparatorString("a", "b"), -1)
+		t.Assert(gtree.ComparatorString("b", "a"), 1)
+		t.Assert(gtree.ComparatorString("a", "a"), 0)
+
+		t.Assert(gtree.ComparatorFloat64(0.1, 0.2), -1)
+		t.Assert(gtree.ComparatorFloat64(math.MaxFloat64, -math.MaxFloat64), 1)
+		t.Assert(gtree.ComparatorFloat64(1.5, 1.5), 0)
+		t.Assert(gtree.ComparatorFloat64(math.NaN(), 1.0), -1)
+		t.Assert(gtree.ComparatorFloat64(1.0, math.NaN()), 1)
+		t.Assert(gtree.ComparatorFloat64(math.NaN(), math.NaN()), 0)
+
+		now := time.Now()
+		t.Assert(gtree.ComparatorTime(now, now.Add(time.Second)), -1)
+		t.Assert(gtree.ComparatorTime(now.Add(time.Second), now), 1)
+		t.Assert(gtree.ComparatorTime(now, now), 0)
+	})
+	// Nil handling.
+	gtest.C(t, func(t *gtest.T) {
+		comparators := []func(a, b interface{}) int{
+			gtree.ComparatorInt,
+			gtree.ComparatorInt64,
+			gtree.ComparatorString,
+			gtree.ComparatorFloat64,
+			gtree.ComparatorTime,
+		}
+		for _, comparator := range comparators {
+			t.Assert(comparator(nil, nil), 0)
+			t.Assert(comparator(nil, 0), -1)
+			t.Assert(comparator(0, nil), 1)
+		}
+	})
+	// Large keys in tree.
+	gtest.C(t, func(t *gtest.T) {
+		tree := gtree.NewRedBlackTree(gtree.ComparatorInt)
+		keys := []interface{}{math.MaxInt64, math.MinInt64, 0, math.MaxInt64 - 1, math.MinInt64 + 1}
+		for _, key := range keys {
+			tree.Set(key, key)
+		}
+		t.Assert(tree.Keys(), []interface{}{math.MinInt64, math.MinInt64 + 1, 0, math.MaxInt64 - 1, math.MaxInt64})
+		t.Assert(tree.Get(math.MinInt64), math.MinInt64)
+	})
+}
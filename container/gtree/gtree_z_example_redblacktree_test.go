@@ -798,3 +798,78 @@ func ExampleRedBlackTree_UnmarshalValue() {
 	// Output:
 	// map[interface {}]interface {}{"Name":"john", "Uid":1, "password1":"123", "password2":"456"}
 }
+
+func ExampleRedBlackTree_Select() {
+	tree := gtree.NewRedBlackTree(gutil.ComparatorInt)
+	for i := 1; i <= 5; i++ {
+		tree.Set(i*10, i)
+	}
+
+	fmt.Println(tree.Select(0))
+	fmt.Println(tree.Select(2))
+	fmt.Println(tree.Select(5))
+
+	// Output:
+	// 10 1 true
+	// 30 3 true
+	// <nil> <nil> false
+}
+
+func ExampleRedBlackTree_Rank() {
+	tree := gtree.NewRedBlackTree(gutil.ComparatorInt)
+	for i := 1; i <= 5; i++ {
+		tree.Set(i*10, i)
+	}
+
+	fmt.Println(tree.Rank(10))
+	fmt.Println(tree.Rank(35))
+	fmt.Println(tree.Rank(100))
+
+	// Output:
+	// 0
+	// 3
+	// 5
+}
+
+func ExampleRedBlackTree_Nearest() {
+	tree := gtree.NewRedBlackTree(gutil.ComparatorInt)
+	for i := 1; i <= 5; i++ {
+		tree.Set(i*10, i)
+	}
+	distance := func(a, b interface{}) int {
+		if d := a.(int) - b.(int); d > 0 {
+			return d
+		} else {
+			return -d
+		}
+	}
+
+	fmt.Println(tree.Nearest(23, distance))
+	fmt.Println(tree.Nearest(27, distance))
+	fmt.Println(tree.Nearest(25, distance))
+	fmt.Println(tree.Nearest(0, distance))
+	fmt.Println(tree.Nearest(100, distance))
+
+	// Output:
+	// 20 2 true
+	// 30 3 true
+	// 20 2 true
+	// 10 1 true
+	// 50 5 true
+}
+
+func ExampleRedBlackTree_Between() {
+	tree := gtree.NewRedBlackTree(gutil.ComparatorInt)
+	for i := 1; i <= 5; i++ {
+		tree.Set(i*10, i)
+	}
+
+	fmt.Println(tree.Between(20, 40, true))
+	fmt.Println(tree.Between(20, 40, false))
+	fmt.Println(tree.Between(60, 70, true))
+
+	// Output:
+	// [{20 2} {30 3} {40 4}]
+	// [{30 3}]
+	// []
+}
@@ -0,0 +1,114 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gtree_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gogf/gf/v2/container/gtree"
+	"github.com/gogf/gf/v2/test/gtest"
+)
+
+func Test_GRedBlackTree_Basic(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		tree := gtree.NewGRedBlackTree[int, string](func(v1, v2 int) int {
+			return v1 - v2
+		}, true)
+		key, value, found := tree.Left()
+		t.Assert(key, 0)
+		t.Assert(value, "")
+		t.Assert(found, false)
+
+		for _, i := range []int{5, 3, 8, 1, 4} {
+			tree.Set(i, strings.Repeat("a", i))
+		}
+		t.Assert(tree.Size(), 5)
+		t.Assert(tree.IsEmpty(), false)
+		t.Assert(tree.Keys(), []int{1, 3, 4, 5, 8})
+		t.Assert(tree.Values(), []string{"a", "aaa", "aaaa", "aaaaa", "aaaaaaaa"})
+
+		var v string
+		v, found = tree.Search(3)
+		t.Assert(v, "aaa")
+		t.Assert(found, true)
+		_, found = tree.Search(2)
+		t.Assert(found, false)
+		t.Assert(tree.Get(1), "a")
+		t.Assert(tree.Get(2), "")
+		t.Assert(tree.Contains(4), true)
+
+		key, value, found = tree.Left()
+		t.Assert(key, 1)
+		t.Assert(value, "a")
+		t.Assert(found, true)
+		key, _, _ = tree.Right()
+		t.Assert(key, 8)
+
+		v, found = tree.Remove(3)
+		t.Assert(v, "aaa")
+		t.Assert(found, true)
+		_, found = tree.Remove(3)
+		t.Assert(found, false)
+
+		var keys []int
+		tree.IteratorDesc(func(key int, value string) bool {
+			keys = append(keys, key)
+			return len(keys) < 2
+		})
+		t.Assert(keys, []int{8, 5})
+
+		tree.Clear()
+		t.Assert(tree.IsEmpty(), true)
+	})
+}
+
+func Test_GRedBlackTree_MinMax(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		tree := gtree.NewGRedBlackTree[string, float64](strings.Compare, true)
+		key, value, found := tree.Min()
+		t.Assert(key, "")
+		t.Assert(value, 0)
+		t.Assert(found, false)
+		key, value, found = tree.Max()
+		t.Assert(key, "")
+		t.Assert(value, 0)
+		t.Assert(found, false)
+
+		tree.Set("b", 2.5)
+		key, value, found = tree.Min()
+		t.Assert(key, "b")
+		t.Assert(value, 2.5)
+		t.Assert(found, true)
+		key, value, found = tree.Max()
+		t.Assert(key, "b")
+		t.Assert(value, 2.5)
+		t.Assert(found, true)
+
+		tree.Set("a", 1)
+		tree.Set("c", 3)
+		key, value, _ = tree.Min()
+		t.Assert(key, "a")
+		t.Assert(value, 1)
+		key, value, _ = tree.Max()
+		t.Assert(key, "c")
+		t.Assert(value, 3)
+	})
+}
+
+func Test_GRedBlackTree_InterfaceValue(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		tree := gtree.NewGRedBlackTree[string, interface{}](strings.Compare)
+		tree.Set("b", nil)
+		tree.Set("a", 1)
+		value, found := tree.Search("b")
+		t.Assert(value, nil)
+		t.Assert(found, true)
+		t.Assert(tree.Keys(), []string{"a", "b"})
+		t.Assert(tree.Values(), []interface{}{1, nil})
+	})
+}
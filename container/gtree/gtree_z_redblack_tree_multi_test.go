@@ -0,0 +1,103 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with gm file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gtree_test
+
+import (
+	"testing"
+
+	"github.com/gogf/gf/v2/container/gtree"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/gutil"
+)
+
+func Test_RedBlackTreeMulti_Basic(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		tree := gtree.NewRedBlackTreeMulti(gutil.ComparatorInt, true)
+		t.Assert(tree.IsEmpty(), true)
+		tree.Set(2, "b1")
+		tree.Set(1, "a1")
+		tree.Set(2, "b2")
+		tree.Set(3, "c1")
+		tree.Set(2, "b3")
+		t.Assert(tree.Size(), 5)
+		t.Assert(tree.DistinctSize(), 3)
+		t.Assert(tree.Keys(), []interface{}{1, 2, 3})
+		t.Assert(tree.Values(), []interface{}{"a1", "b1", "b2", "b3", "c1"})
+		t.Assert(tree.SearchAll(2), []interface{}{"b1", "b2", "b3"})
+		t.Assert(tree.SearchAll(4), nil)
+		t.Assert(tree.Count(2), 3)
+		t.Assert(tree.Count(4), 0)
+		t.Assert(tree.Contains(3), true)
+
+		value, found := tree.Search(2)
+		t.Assert(value, "b1")
+		t.Assert(found, true)
+		_, found = tree.Search(4)
+		t.Assert(found, false)
+
+		var values []interface{}
+		tree.IteratorDesc(func(key, value interface{}) bool {
+			values = append(values, value)
+			return key != 2
+		})
+		t.Assert(values, []interface{}{"c1", "b3"})
+
+		value, found = tree.Remove(2)
+		t.Assert(value, "b1")
+		t.Assert(found, true)
+		t.Assert(tree.SearchAll(2), []interface{}{"b2", "b3"})
+		value, found = tree.Remove(1)
+		t.Assert(value, "a1")
+		t.Assert(found, true)
+		t.Assert(tree.Contains(1), false)
+		_, found = tree.Remove(1)
+		t.Assert(found, false)
+		t.Assert(tree.Size(), 3)
+
+		t.Assert(tree.RemoveAll(2), []interface{}{"b2", "b3"})
+		t.Assert(tree.RemoveAll(2), nil)
+		t.Assert(tree.Size(), 1)
+		t.Assert(tree.Keys(), []interface{}{3})
+
+		tree.Clear()
+		t.Assert(tree.IsEmpty(), true)
+		t.Assert(tree.DistinctSize(), 0)
+	})
+}
+
+func Test_RedBlackTreeMulti_DistinctSize(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		tree := gtree.NewRedBlackTreeMulti(gutil.ComparatorInt, true)
+		for i := 0; i < 100; i++ {
+			tree.Set(i%10, i)
+		}
+		t.Assert(tree.Size(), 100)
+		t.Assert(tree.DistinctSize(), 10)
+
+		tree.Remove(0)
+		t.Assert(tree.Size(), 99)
+		t.Assert(tree.DistinctSize(), 10)
+		tree.RemoveAll(1)
+		t.Assert(tree.Size(), 89)
+		t.Assert(tree.DistinctSize(), 9)
+		for i := 0; i < 9; i++ {
+			tree.Remove(0)
+		}
+		t.Assert(tree.Size(), 80)
+		t.Assert(tree.DistinctSize(), 8)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		tree := gtree.NewRedBlackTree(gutil.ComparatorInt)
+		for i := 0; i < 100; i++ {
+			tree.Set(i%10, i)
+		}
+		t.Assert(tree.Size(), 10)
+		t.Assert(tree.DistinctSize(), 10)
+		tree.Remove(0)
+		t.Assert(tree.DistinctSize(), 9)
+	})
+}
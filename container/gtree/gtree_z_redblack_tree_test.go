@@ -7,12 +7,19 @@
 package gtree_test
 
 import (
+	"bytes"
 	"fmt"
+	"math"
+	"sort"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gogf/gf/v2/container/gtree"
+	"github.com/gogf/gf/v2/container/gtype"
 	"github.com/gogf/gf/v2/container/gvar"
 	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/grand"
 	"github.com/gogf/gf/v2/util/gutil"
 )
 
@@ -292,3 +299,615 @@ func Test_RedBlackTree_Remove(t *testing.T) {
 		}
 	})
 }
+
+func Test_RedBlackTree_Remove_Predecessor(t *testing.T) {
+	// Removing the node having two children replaces it with its predecessor, which is the
+	// right-most node of the left subtree, and the right spine of the subtree can be deeper than 2.
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			tree = gtree.NewRedBlackTree(gutil.ComparatorInt)
+			keys = make([]interface{}, 0, 100)
+		)
+		for i := 0; i < 100; i++ {
+			tree.Set(i, i*10)
+			keys = append(keys, i)
+		}
+		for i := 0; i < 100; i++ {
+			key := i * 37 % 100
+			t.Assert(tree.Remove(key), key*10)
+			for j, k := range keys {
+				if k == key {
+					keys = append(keys[:j], keys[j+1:]...)
+					break
+				}
+			}
+			t.Assert(tree.Keys(), keys)
+			for _, k := range keys {
+				t.Assert(tree.Get(k), k.(int)*10)
+			}
+		}
+	})
+}
+
+func Test_RedBlackTree_Search(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gtree.NewRedBlackTree(gutil.ComparatorString)
+		m.Set("key1", "val1")
+		m.Set("nil", nil)
+
+		v, found := m.Search("key1")
+		t.Assert(found, true)
+		t.Assert(v, "val1")
+
+		v, found = m.Search("nil")
+		t.Assert(found, true)
+		t.Assert(v, nil)
+
+		v, found = m.Search("none")
+		t.Assert(found, false)
+		t.Assert(v, nil)
+
+		t.Assert(m.Get("key1"), "val1")
+		t.Assert(m.Get("nil"), nil)
+		t.Assert(m.Get("none"), nil)
+		t.Assert(m.Contains("nil"), true)
+		t.Assert(m.Contains("none"), false)
+	})
+}
+
+func Test_RedBlackTree_SelectRank(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			tree      = gtree.NewRedBlackTree(gutil.ComparatorInt)
+			reference = make(map[int]int)
+		)
+		k, v, found := tree.Select(0)
+		t.Assert(found, false)
+		t.Assert(k, nil)
+		t.Assert(v, nil)
+		t.Assert(tree.Rank(1), 0)
+
+		for i := 0; i < 3000; i++ {
+			key := grand.N(0, 500)
+			if grand.N(0, 2) == 0 {
+				tree.Remove(key)
+				delete(reference, key)
+			} else {
+				tree.Set(key, key*10)
+				reference[key] = key * 10
+			}
+			if i%100 != 0 {
+				continue
+			}
+			keys := make([]int, 0, len(reference))
+			for key := range reference {
+				keys = append(keys, key)
+			}
+			sort.Ints(keys)
+			t.Assert(tree.Size(), len(keys))
+			for index, key := range keys {
+				k, v, found = tree.Select(index)
+				t.Assert(found, true)
+				t.Assert(k, key)
+				t.Assert(v, key*10)
+				t.Assert(tree.Rank(key), index)
+				t.Assert(tree.Rank(key+1), sort.SearchInts(keys, key+1))
+			}
+			_, _, found = tree.Select(len(keys))
+			t.Assert(found, false)
+			_, _, found = tree.Select(-1)
+			t.Assert(found, false)
+			t.Assert(tree.Rank(-1), 0)
+			t.Assert(tree.Rank(1000), len(keys))
+		}
+	})
+}
+
+func Test_RedBlackTree_SetIfNotExist(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		m := gtree.NewRedBlackTree(gutil.ComparatorString)
+		t.Assert(m.SetIfNotExist("nil", nil), true)
+		t.Assert(m.Contains("nil"), true)
+		t.Assert(m.SetIfNotExist("nil", 1), false)
+		t.Assert(m.Get("nil"), nil)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			m     = gtree.NewRedBlackTree(gutil.ComparatorInt, true)
+			wg    = sync.WaitGroup{}
+			added = gtype.NewInt()
+		)
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				if m.SetIfNotExist(1, i) {
+					added.Add(1)
+				}
+			}(i)
+		}
+		wg.Wait()
+		t.Assert(added.Val(), 1)
+		t.Assert(m.Size(), 1)
+	})
+	// The function is executed with the lock only if the key does not exist.
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			m       = gtree.NewRedBlackTree(gutil.ComparatorInt, true)
+			wg      = sync.WaitGroup{}
+			calls   = gtype.NewInt()
+			running = gtype.NewInt()
+		)
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				m.SetIfNotExistFunc(i%10, func() interface{} {
+					t.Assert(running.Add(1), 1)
+					defer running.Add(-1)
+					calls.Add(1)
+					time.Sleep(time.Millisecond)
+					return i
+				})
+			}(i)
+		}
+		wg.Wait()
+		t.Assert(calls.Val(), 10)
+		t.Assert(m.Size(), 10)
+		t.Assert(m.SetIfNotExistFunc(1, func() interface{} {
+			t.Error("should not be called")
+			return nil
+		}), false)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		m := gtree.NewRedBlackTree(gutil.ComparatorInt, true)
+		m.Sets(map[interface{}]interface{}{1: 1, 2: 2, 3: 3})
+		t.Assert(m.Keys(), []interface{}{1, 2, 3})
+		t.Assert(m.Values(), []interface{}{1, 2, 3})
+	})
+}
+
+func Test_RedBlackTree_GetOrSetFunc(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			m     = gtree.NewRedBlackTree(gutil.ComparatorInt)
+			calls = 0
+			f     = func() interface{} {
+				calls++
+				return calls
+			}
+		)
+		t.Assert(m.Contains(1), false)
+		t.Assert(m.GetOrSetFunc(1, f), 1)
+		t.Assert(m.GetOrSetFunc(1, f), 1)
+		t.Assert(m.GetOrSetFuncLock(1, f), 1)
+		t.Assert(calls, 1)
+		t.Assert(m.Contains(1), true)
+
+		t.Assert(m.GetOrSet(2, "2"), "2")
+		t.Assert(m.GetOrSet(2, "3"), "2")
+		t.Assert(m.Size(), 2)
+	})
+}
+
+func Test_RedBlackTree_Flip(t *testing.T) {
+	comparatorInt := func(v1, v2 interface{}) int {
+		return v1.(int) - v2.(int)
+	}
+	gtest.C(t, func(t *gtest.T) {
+		tree := gtree.NewRedBlackTreeFrom(comparatorInt, map[interface{}]interface{}{1: "a", 2: "b", 3: "c"})
+		t.AssertNE(tree.Flip(), nil)
+		t.Assert(tree.Map(), map[interface{}]interface{}{1: "a", 2: "b", 3: "c"})
+		t.Assert(tree.Get(2), "b")
+
+		t.Assert(tree.Flip(gutil.ComparatorString), nil)
+		t.Assert(tree.Map(), map[interface{}]interface{}{"a": 1, "b": 2, "c": 3})
+		t.Assert(tree.Keys(), []interface{}{"a", "b", "c"})
+		t.Assert(tree.Get("b"), 2)
+
+		// The new comparator is kept after flipping.
+		tree.Set("d", 4)
+		t.Assert(tree.Keys(), []interface{}{"a", "b", "c", "d"})
+	})
+	// Duplicated values.
+	gtest.C(t, func(t *gtest.T) {
+		tree := gtree.NewRedBlackTreeFrom(comparatorInt, map[interface{}]interface{}{1: 10, 2: 10, 3: 30})
+		t.Assert(tree.Flip(), nil)
+		t.Assert(tree.Map(), map[interface{}]interface{}{10: 2, 30: 3})
+		t.Assert(tree.Size(), 2)
+	})
+}
+
+func Test_RedBlackTree_Nearest(t *testing.T) {
+	distance := func(a, b interface{}) int {
+		if d := a.(int) - b.(int); d > 0 {
+			return d
+		} else {
+			return -d
+		}
+	}
+	gtest.C(t, func(t *gtest.T) {
+		tree := gtree.NewRedBlackTree(gutil.ComparatorInt, true)
+		key, value, found := tree.Nearest(1, distance)
+		t.Assert(key, nil)
+		t.Assert(value, nil)
+		t.Assert(found, false)
+
+		tree.Sets(map[interface{}]interface{}{10: "a", 20: "b", 40: "c"})
+		key, value, found = tree.Nearest(20, distance)
+		t.Assert(key, 20)
+		t.Assert(value, "b")
+		t.Assert(found, true)
+		key, _, _ = tree.Nearest(31, distance)
+		t.Assert(key, 40)
+		key, _, _ = tree.Nearest(29, distance)
+		t.Assert(key, 20)
+		key, _, _ = tree.Nearest(-100, distance)
+		t.Assert(key, 10)
+		key, _, _ = tree.Nearest(100, distance)
+		t.Assert(key, 40)
+	})
+}
+
+func Test_RedBlackTree_Concurrent(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		tree := gtree.NewRedBlackTree(gutil.ComparatorInt, true)
+		t.Assert(tree.Left(), nil)
+		t.Assert(tree.Right(), nil)
+
+		var (
+			wg    = sync.WaitGroup{}
+			count = 1000
+		)
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := i; j < count; j += 4 {
+					tree.Set(j, j)
+					tree.Search(j)
+					tree.Left()
+					tree.Right()
+					tree.Iterator(func(key, value interface{}) bool {
+						return false
+					})
+					if j%2 == 1 {
+						tree.Remove(j)
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+		t.Assert(tree.Size(), count/2)
+		t.Assert(tree.Left().Key, 0)
+		t.Assert(tree.Right().Key, count-2)
+		t.Assert(tree.Clone().Keys(), tree.Keys())
+	})
+}
+
+func Test_RedBlackTree_Between(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		tree := gtree.NewRedBlackTree(gutil.ComparatorInt, true)
+		t.Assert(tree.Between(1, 10, true), []gtree.Entry{})
+		for i := 1; i <= 10; i++ {
+			tree.Set(i*10, i)
+		}
+		t.Assert(tree.Between(20, 40, true), []gtree.Entry{{20, 2}, {30, 3}, {40, 4}})
+		t.Assert(tree.Between(20, 40, false), []gtree.Entry{{30, 3}})
+		t.Assert(tree.Between(15, 45, false), []gtree.Entry{{20, 2}, {30, 3}, {40, 4}})
+		t.Assert(tree.Between(0, 10, true), []gtree.Entry{{10, 1}})
+		t.Assert(tree.Between(100, 1000, true), []gtree.Entry{{100, 10}})
+		t.Assert(tree.Between(20, 20, true), []gtree.Entry{{20, 2}})
+		t.Assert(tree.Between(20, 20, false), []gtree.Entry{})
+		t.Assert(tree.Between(21, 29, true), []gtree.Entry{})
+		t.Assert(tree.Between(40, 20, true), []gtree.Entry{})
+		t.Assert(len(tree.Between(0, 1000, true)), 10)
+	})
+}
+
+func Test_RedBlackTree_Output(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			tree   = gtree.NewRedBlackTree(gutil.ComparatorInt)
+			buffer = bytes.NewBuffer(nil)
+		)
+		tree.Output(buffer)
+		t.Assert(buffer.String(), "\n")
+
+		for i := 1; i <= 3; i++ {
+			tree.Set(i, i)
+		}
+		buffer.Reset()
+		tree.Output(buffer)
+		t.Assert(buffer.String(), tree.String()+"\n")
+		t.Assert(buffer.String(), "│   ┌── 3\n└── 2\n    └── 1\n\n")
+	})
+}
+
+func Test_RedBlackTree_PredecessorSuccessor(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		tree := gtree.NewRedBlackTree(gutil.ComparatorInt, true)
+		_, _, found := tree.Predecessor(1)
+		t.Assert(found, false)
+		_, _, found = tree.Successor(1)
+		t.Assert(found, false)
+
+		for i := 1; i <= 100; i++ {
+			tree.Set(i*2, i)
+		}
+		for i := 1; i <= 100; i++ {
+			key := i * 2
+			k, v, found := tree.Predecessor(key)
+			if i == 1 {
+				t.Assert(found, false)
+				t.Assert(k, nil)
+			} else {
+				t.Assert(found, true)
+				t.Assert(k, key-2)
+				t.Assert(v, i-1)
+			}
+			k, v, found = tree.Successor(key)
+			if i == 100 {
+				t.Assert(found, false)
+				t.Assert(v, nil)
+			} else {
+				t.Assert(found, true)
+				t.Assert(k, key+2)
+				t.Assert(v, i+1)
+			}
+			// Keys that are not in the tree.
+			k, _, _ = tree.Predecessor(key + 1)
+			t.Assert(k, key)
+			k, _, _ = tree.Successor(key - 1)
+			t.Assert(k, key)
+		}
+	})
+}
+
+func Test_RedBlackTree_FuncLock(t *testing.T) {
+	// The function `f` is executed within the write lock of the tree.
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			tree    = gtree.NewRedBlackTree(gutil.ComparatorInt, true)
+			done    = make(chan struct{})
+			blocked = false
+		)
+		t.Assert(tree.SetIfNotExistFuncLock(1, func() interface{} {
+			go func() {
+				tree.Set(2, 2)
+				close(done)
+			}()
+			time.Sleep(50 * time.Millisecond)
+			select {
+			case <-done:
+			default:
+				blocked = true
+			}
+			return 1
+		}), true)
+		<-done
+		t.Assert(blocked, true)
+		t.Assert(tree.Map(), map[interface{}]interface{}{1: 1, 2: 2})
+	})
+	// The function `f` is executed only once by concurrent callers.
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			tree  = gtree.NewRedBlackTree(gutil.ComparatorInt, true)
+			wg    = sync.WaitGroup{}
+			calls = 0
+			f     = func() interface{} {
+				calls++
+				return calls
+			}
+		)
+		for i := 0; i < 100; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				tree.GetOrSetFuncLock(1, f)
+			}()
+			go func() {
+				defer wg.Done()
+				tree.SetIfNotExistFuncLock(2, f)
+			}()
+		}
+		wg.Wait()
+		t.Assert(calls, 2)
+		t.Assert(tree.Size(), 2)
+		t.Assert(tree.SetIfNotExistFunc(1, f), false)
+		t.Assert(calls, 2)
+	})
+}
+
+func Test_RedBlackTree_IteratorAscSnapshot(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		tree := gtree.NewRedBlackTree(gutil.ComparatorInt, true)
+		for i := 5; i > 0; i-- {
+			tree.Set(i, i*10)
+		}
+		// The tree can be changed in `f` without deadlock.
+		var keys []interface{}
+		tree.IteratorAscSnapshot(func(key, value interface{}) bool {
+			keys = append(keys, key)
+			tree.Set(key.(int)+10, value)
+			return key.(int) < 4
+		})
+		t.Assert(keys, []interface{}{1, 2, 3, 4})
+		t.Assert(tree.Size(), 9)
+		t.Assert(tree.Get(14), 40)
+	})
+}
+
+func Test_RedBlackTree_GetOrDefault_GetVar(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		tree := gtree.NewRedBlackTree(gutil.ComparatorInt, true)
+		tree.Set(1, 10)
+		tree.Set(2, nil)
+		t.Assert(tree.GetOrDefault(1, 100), 10)
+		t.Assert(tree.GetOrDefault(2, 100), nil)
+		t.Assert(tree.GetOrDefault(3, 100), 100)
+		t.Assert(tree.Contains(3), false)
+
+		t.Assert(tree.GetVar(1).Int(), 10)
+		t.Assert(tree.GetVar(2).IsNil(), true)
+		t.Assert(tree.GetVar(3).IsNil(), true)
+	})
+}
+
+func Test_RedBlackTree_CountRange(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		tree := gtree.NewRedBlackTree(gutil.ComparatorInt, true)
+		t.Assert(tree.CountRange(0, 10, true), 0)
+		for i := 0; i < 100; i += 2 {
+			tree.Set(i, i)
+		}
+		for _, c := range []struct {
+			low, high int
+		}{{10, 20}, {11, 19}, {-10, 200}, {20, 10}, {10, 10}, {11, 11}, {98, 120}} {
+			for _, inclusive := range []bool{true, false} {
+				t.Assert(tree.CountRange(c.low, c.high, inclusive), len(tree.Between(c.low, c.high, inclusive)))
+			}
+		}
+		t.Assert(tree.CountRange(10, 20, true), 6)
+		t.Assert(tree.CountRange(10, 20, false), 4)
+		t.Assert(tree.CountRange(10, 10, true), 1)
+		t.Assert(tree.CountRange(10, 10, false), 0)
+		t.Assert(tree.CountRange(20, 10, true), 0)
+	})
+}
+
+func Test_RedBlackTree_Flatten(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		tree := gtree.NewRedBlackTree(gutil.ComparatorInt, true)
+		t.Assert(tree.Flatten(), []gtree.Entry{})
+		tree.Sets(map[interface{}]interface{}{3: "c", 1: "a", 2: "b"})
+		t.Assert(tree.Flatten(), []gtree.Entry{{Key: 1, Value: "a"}, {Key: 2, Value: "b"}, {Key: 3, Value: "c"}})
+	})
+}
+
+func Test_RedBlackTree_Dump_Load(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			buffer bytes.Buffer
+			tree   = gtree.NewRedBlackTree(gutil.ComparatorInt, true)
+		)
+		for i := 0; i < 1000; i++ {
+			tree.Set(i, fmt.Sprint("v", i))
+		}
+		tree.Set(1000, nil)
+		t.AssertNil(tree.Dump(&buffer))
+
+		loaded := gtree.NewRedBlackTree(gutil.ComparatorInt)
+		loaded.Set(-1, -1)
+		t.AssertNil(loaded.Load(&buffer))
+		t.AssertNil(loaded.Check())
+		t.Assert(loaded.Size(), 1001)
+		t.Assert(loaded.Keys(), tree.Keys())
+		t.Assert(loaded.Values(), tree.Values())
+		t.Assert(loaded.Contains(1000), true)
+		t.Assert(loaded.Get(1000), nil)
+
+		buffer.Reset()
+		t.AssertNil(gtree.NewRedBlackTree(gutil.ComparatorInt).Dump(&buffer))
+		t.AssertNil(loaded.Load(&buffer))
+		t.Assert(loaded.Size(), 0)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			buffer bytes.Buffer
+			tree   = gtree.NewRedBlackTreeFrom(gutil.ComparatorInt, map[interface{}]interface{}{1: 1, 2: 2, 3: 3})
+		)
+		t.AssertNil(tree.Dump(&buffer))
+		data := buffer.Bytes()
+
+		// The keys are not sorted by the comparator of the loading tree.
+		loaded := gtree.NewRedBlackTree(func(a, b interface{}) int {
+			return gutil.ComparatorInt(b, a)
+		})
+		loaded.Set(0, 0)
+		t.AssertNE(loaded.Load(bytes.NewReader(data)), nil)
+		t.Assert(loaded.Keys(), []interface{}{0})
+
+		// The data is truncated.
+		loaded = gtree.NewRedBlackTree(gutil.ComparatorInt)
+		t.AssertNE(loaded.Load(bytes.NewReader(data[:len(data)-1])), nil)
+		t.AssertNE(loaded.Load(bytes.NewReader(nil)), nil)
+		t.Assert(loaded.Size(), 0)
+
+		t.AssertNE(gtree.NewRedBlackTree(nil).Load(bytes.NewReader(data)), nil)
+	})
+}
+
+func Test_RedBlackTree_RangePage(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		tree := gtree.NewRedBlackTree(gutil.ComparatorInt, true)
+		entries, nextKey, hasMore := tree.RangePage(0, 10)
+		t.Assert(entries, nil)
+		t.Assert(nextKey, nil)
+		t.Assert(hasMore, false)
+
+		for i := 0; i < 50; i += 2 {
+			tree.Set(i, i*10)
+		}
+		entries, nextKey, hasMore = tree.RangePage(3, 3)
+		t.Assert(entries, []gtree.Entry{{Key: 4, Value: 40}, {Key: 6, Value: 60}, {Key: 8, Value: 80}})
+		t.Assert(nextKey, 10)
+		t.Assert(hasMore, true)
+
+		entries, nextKey, hasMore = tree.RangePage(44, 3)
+		t.Assert(entries, []gtree.Entry{{Key: 44, Value: 440}, {Key: 46, Value: 460}, {Key: 48, Value: 480}})
+		t.Assert(nextKey, nil)
+		t.Assert(hasMore, false)
+
+		entries, nextKey, hasMore = tree.RangePage(49, 3)
+		t.Assert(len(entries), 0)
+		t.Assert(hasMore, false)
+
+		entries, nextKey, hasMore = tree.RangePage(1, 0)
+		t.Assert(len(entries), 0)
+		t.Assert(nextKey, 2)
+		t.Assert(hasMore, true)
+
+		// Iterating all the pages.
+		var (
+			keys []interface{}
+			low  interface{} = -1
+		)
+		for {
+			entries, nextKey, hasMore = tree.RangePage(low, 4)
+			for _, entry := range entries {
+				keys = append(keys, entry.Key)
+			}
+			if !hasMore {
+				break
+			}
+			low = nextKey
+		}
+		t.Assert(keys, tree.Keys())
+	})
+}
+
+func Test_RedBlackTree_WithinFloat(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		tree := gtree.NewRedBlackTree(gtree.ComparatorFloat64, true)
+		for _, key := range []float64{-2.5, -1, 0.5, 1, 1.4, 1.6, 3} {
+			tree.Set(key, key*10)
+		}
+		t.Assert(tree.WithinFloat(1, 0.5), []gtree.Entry{{Key: 0.5, Value: 5}, {Key: 1, Value: 10}, {Key: 1.4, Value: 14}})
+		t.Assert(tree.WithinFloat(-2, 0.5), []gtree.Entry{{Key: -2.5, Value: -25}})
+		t.Assert(tree.WithinFloat(1, 0), []gtree.Entry{{Key: 1, Value: 10}})
+		t.Assert(len(tree.WithinFloat(10, 1)), 0)
+		t.Assert(len(tree.WithinFloat(1, -1)), 0)
+		t.Assert(len(tree.WithinFloat(1, math.NaN())), 0)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		tree := gtree.NewRedBlackTree(gtree.ComparatorInt, true)
+		for i := -10; i <= 10; i++ {
+			tree.Set(i, i)
+		}
+		t.Assert(tree.WithinFloat(5, 0.5), []gtree.Entry{{Key: 5, Value: 5}})
+		t.Assert(tree.WithinFloat(-5, 1.5), []gtree.Entry{{Key: -6, Value: -6}, {Key: -5, Value: -5}, {Key: -4, Value: -4}})
+		t.Assert(len(tree.WithinFloat(4.5, 0.4)), 0)
+		t.Assert(tree.WithinFloat(9.5, 1), []gtree.Entry{{Key: 9, Value: 9}, {Key: 10, Value: 10}})
+	})
+}
@@ -0,0 +1,457 @@
+// Copyright GoFrame Author(https://goframe.org). All Rights Reserved.
+//
+// This Source Code Form is subject to the terms of the MIT License.
+// If a copy of the MIT was not distributed with this file,
+// You can obtain one at https://github.com/gogf/gf.
+
+package gtree
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/test/gtest"
+	"github.com/gogf/gf/v2/util/gutil"
+)
+
+func Test_RedBlackTree_BuildFromSorted(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		for n := 0; n <= 300; n++ {
+			var (
+				tree   = NewRedBlackTree(gutil.ComparatorInt)
+				keys   = make([]interface{}, n)
+				values = make([]interface{}, n)
+			)
+			tree.Set(-1, -1)
+			for i := 0; i < n; i++ {
+				keys[i] = i
+				values[i] = i * 10
+			}
+			t.AssertNil(tree.BuildFromSorted(keys, values))
+			t.AssertNil(tree.Check())
+			t.Assert(tree.Size(), n)
+			t.Assert(tree.Keys(), keys)
+			t.Assert(tree.Values(), values)
+
+			// The tree keeps valid after changing.
+			for i := 0; i < n; i += 3 {
+				tree.Remove(i)
+				tree.Set(n+i, i)
+			}
+			t.AssertNil(tree.Check())
+		}
+	})
+	gtest.C(t, func(t *gtest.T) {
+		tree := NewRedBlackTree(gutil.ComparatorInt, true)
+		tree.Set(100, 100)
+		t.AssertNE(tree.BuildFromSorted([]interface{}{1, 2}, []interface{}{1}), nil)
+		t.AssertNE(tree.BuildFromSorted([]interface{}{1, 3, 2}, []interface{}{1, 3, 2}), nil)
+		t.Assert(tree.Map(), map[interface{}]interface{}{100: 100})
+
+		// Adjacent duplicated keys.
+		t.AssertNil(tree.BuildFromSorted([]interface{}{1, 1, 2, 3, 3, 3}, []interface{}{1, 2, 3, 4, 5, 6}))
+		t.AssertNil(tree.Check())
+		t.Assert(tree.Keys(), []interface{}{1, 2, 3})
+		t.Assert(tree.Values(), []interface{}{2, 3, 6})
+	})
+}
+
+func Test_RedBlackTree_Check(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		tree := NewRedBlackTree(gutil.ComparatorInt)
+		t.AssertNil(tree.Check())
+		for i := 0; i < 100; i++ {
+			tree.Set(i, i)
+		}
+		t.AssertNil(tree.Check())
+
+		tree.root.color = red
+		t.Assert(gerror.Code(tree.Check()), gcode.CodeInternalError)
+		tree.root.color = black
+		t.AssertNil(tree.Check())
+
+		// Swapping the keys breaks the order.
+		left, right := tree.leftNode(), tree.rightNode()
+		left.Key, right.Key = right.Key, left.Key
+		t.Assert(strings.Contains(tree.Check().Error(), "are not in ascending order"), true)
+		left.Key, right.Key = right.Key, left.Key
+		t.AssertNil(tree.Check())
+
+		left.size++
+		t.AssertNE(tree.Check(), nil)
+		left.size--
+
+		// Recoloring a black leaf breaks the black height.
+		node := tree.leftNode()
+		for node.color != black {
+			node = node.parent
+		}
+		node.color = red
+		t.AssertNE(tree.Check(), nil)
+		node.color = black
+		t.AssertNil(tree.Check())
+
+		tree.size++
+		t.AssertNE(tree.Check(), nil)
+	})
+}
+
+func Test_RedBlackTree_RemoveMin_RemoveMax_RemoveRange(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		tree := NewRedBlackTree(gutil.ComparatorInt, true)
+		_, _, found := tree.RemoveMin()
+		t.Assert(found, false)
+		_, _, found = tree.RemoveMax()
+		t.Assert(found, false)
+		t.Assert(tree.RemoveRange(0, 10, true), 0)
+
+		for i := 0; i < 1000; i++ {
+			tree.Set(i, i*10)
+		}
+		key, value, found := tree.RemoveMin()
+		t.Assert(key, 0)
+		t.Assert(value, 0)
+		t.Assert(found, true)
+		key, value, found = tree.RemoveMax()
+		t.Assert(key, 999)
+		t.Assert(value, 9990)
+		t.Assert(found, true)
+		t.AssertNil(tree.Check())
+		t.Assert(tree.Size(), 998)
+
+		t.Assert(tree.RemoveRange(100, 200, true), 101)
+		t.AssertNil(tree.Check())
+		t.Assert(tree.Contains(100), false)
+		t.Assert(tree.Contains(200), false)
+		t.Assert(tree.Contains(99), true)
+		t.Assert(tree.Contains(201), true)
+
+		t.Assert(tree.RemoveRange(300, 400, false), 99)
+		t.AssertNil(tree.Check())
+		t.Assert(tree.Contains(300), true)
+		t.Assert(tree.Contains(400), true)
+		t.Assert(tree.Contains(301), false)
+
+		t.Assert(tree.RemoveRange(150, 250, true), 50)
+		t.Assert(tree.Size(), 998-101-99-50)
+		for !tree.IsEmpty() {
+			tree.RemoveMin()
+			t.AssertNil(tree.Check())
+		}
+	})
+}
+
+func Test_RedBlackTree_Compact(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		tree := NewRedBlackTree(gutil.ComparatorInt, true)
+		tree.Compact()
+		t.Assert(tree.Size(), 0)
+		t.AssertNil(tree.Check())
+
+		for i := 0; i < 1000; i++ {
+			tree.Set(i, i*10)
+		}
+		for i := 0; i < 1000; i += 3 {
+			tree.Remove(i)
+		}
+		var (
+			keys   = tree.Keys()
+			values = tree.Values()
+			root   = tree.root
+		)
+		tree.Compact()
+		t.AssertNil(tree.Check())
+		t.Assert(tree.root != root, true)
+		t.Assert(tree.Keys(), keys)
+		t.Assert(tree.Values(), values)
+
+		// The tree keeps working after compacting.
+		tree.Set(0, 0)
+		tree.Remove(1)
+		t.AssertNil(tree.Check())
+		t.Assert(tree.Get(0), 0)
+		t.Assert(tree.Contains(1), false)
+	})
+}
+
+func Test_RedBlackTree_Removes(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		tree := NewRedBlackTree(gutil.ComparatorInt, true)
+		for i := 0; i < 1000; i++ {
+			tree.Set(i, i*10)
+		}
+		// Removes the even keys, along with the keys not in the tree.
+		keys := make([]interface{}, 0, 600)
+		for i := 0; i < 1200; i += 2 {
+			keys = append(keys, i)
+		}
+		tree.Removes(keys)
+		t.AssertNil(tree.Check())
+		t.Assert(tree.Size(), 500)
+		for i := 0; i < 1000; i++ {
+			t.Assert(tree.Contains(i), i%2 == 1)
+		}
+
+		tree.Removes(nil)
+		tree.Removes([]interface{}{-1, 0, 1, 1})
+		t.AssertNil(tree.Check())
+		t.Assert(tree.Size(), 499)
+		t.Assert(tree.Left().Key, 3)
+	})
+}
+
+func Test_RedBlackTree_IteratorLevel(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		tree := NewRedBlackTree(gutil.ComparatorInt, true)
+		tree.IteratorLevel(func(level int, key, value interface{}) bool {
+			t.Error("should not be called")
+			return true
+		})
+		for i := 0; i < 100; i++ {
+			tree.Set(i, i*10)
+		}
+		var (
+			count    = 0
+			lastKey  = -1
+			maxLevel = 0
+		)
+		tree.IteratorLevel(func(level int, key, value interface{}) bool {
+			if count == 0 {
+				t.Assert(level, 0)
+				t.Assert(key, tree.root.Key)
+			}
+			t.Assert(level >= maxLevel, true)
+			if level > maxLevel {
+				maxLevel, lastKey = level, -1
+			}
+			// The nodes of the same level are in ascending order.
+			t.Assert(key.(int) > lastKey, true)
+			lastKey = key.(int)
+			t.Assert(value, key.(int)*10)
+
+			// The level is the depth of the node.
+			depth := 0
+			node, _ := tree.doSearch(key)
+			for ; node.parent != nil; node = node.parent {
+				depth++
+			}
+			t.Assert(level, depth)
+			count++
+			return true
+		})
+		t.Assert(count, 100)
+		t.Assert(maxLevel > 0, true)
+
+		count = 0
+		tree.IteratorLevel(func(level int, key, value interface{}) bool {
+			count++
+			return count < 3
+		})
+		t.Assert(count, 3)
+	})
+}
+
+func Test_RedBlackTree_Resort(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		tree := NewRedBlackTree(gutil.ComparatorInt, true)
+		tree.Resort(gutil.ComparatorString)
+		t.Assert(tree.Size(), 0)
+
+		for i := 0; i < 200; i++ {
+			tree.Set(i, i*10)
+		}
+		// Descending order by int.
+		tree.Resort(func(a, b interface{}) int {
+			return gutil.ComparatorInt(b, a)
+		})
+		t.AssertNil(tree.Check())
+		t.Assert(tree.Size(), 200)
+		t.Assert(tree.Left().Key, 199)
+		t.Assert(tree.Right().Key, 0)
+		t.Assert(tree.Get(100), 1000)
+
+		tree.Resort(gutil.ComparatorString)
+		t.AssertNil(tree.Check())
+		t.Assert(tree.Keys()[:4], []interface{}{0, 1, 10, 100})
+
+		// The tree keeps working with the new comparator.
+		tree.Set(200, 2000)
+		tree.Remove(0)
+		t.AssertNil(tree.Check())
+		t.Assert(tree.Keys()[:4], []interface{}{1, 10, 100, 101})
+	})
+	// The keys being equal by the new comparator.
+	gtest.C(t, func(t *gtest.T) {
+		tree := NewRedBlackTree(gutil.ComparatorInt)
+		for i := 0; i < 100; i++ {
+			tree.Set(i, i*10)
+		}
+		tree.Resort(func(a, b interface{}) int {
+			return gutil.ComparatorInt(a.(int)%10, b.(int)%10)
+		})
+		t.AssertNil(tree.Check())
+		t.Assert(tree.Keys(), []interface{}{90, 91, 92, 93, 94, 95, 96, 97, 98, 99})
+		t.Assert(tree.Get(5), 950)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		tree := NewRedBlackTree(gutil.ComparatorInt)
+		for i := 0; i < 50; i++ {
+			tree.Set(i, i)
+		}
+		tree.SetComparator(func(a, b interface{}) int {
+			return gutil.ComparatorInt(b, a)
+		})
+		t.AssertNil(tree.Check())
+		t.Assert(tree.Size(), 50)
+		t.Assert(tree.Left().Key, 49)
+	})
+}
+
+func Test_RedBlackTree_COWClone(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		tree := NewRedBlackTree(gutil.ComparatorInt, true)
+		for i := 0; i < 100; i++ {
+			tree.Set(i, i*10)
+		}
+		clone := tree.COWClone()
+		t.Assert(clone.root == tree.root, true)
+		t.Assert(clone.Map(), tree.Map())
+
+		// Removing the missing key does not copy the nodes.
+		clone.Remove(100)
+		t.Assert(clone.root == tree.root, true)
+
+		clone.Set(0, -1)
+		clone.Remove(1)
+		t.Assert(clone.root != tree.root, true)
+		t.AssertNil(clone.Check())
+		t.AssertNil(tree.Check())
+		t.Assert(tree.Get(0), 0)
+		t.Assert(tree.Get(1), 10)
+		t.Assert(clone.Get(0), -1)
+		t.Assert(clone.Contains(1), false)
+
+		// The source tree copies the shared nodes on its first change.
+		snapshot := tree.COWClone()
+		for i := 0; i < 100; i += 2 {
+			tree.Remove(i)
+		}
+		tree.Set(100, 1000)
+		t.AssertNil(tree.Check())
+		t.AssertNil(snapshot.Check())
+		t.Assert(tree.Size(), 51)
+		t.Assert(snapshot.Size(), 100)
+		for i := 0; i < 100; i++ {
+			t.Assert(snapshot.Get(i), i*10)
+		}
+		t.Assert(snapshot.Contains(100), false)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		tree := NewRedBlackTree(gutil.ComparatorInt)
+		clone := tree.COWClone()
+		clone.Set(1, 1)
+		t.Assert(tree.Size(), 0)
+		t.Assert(clone.Size(), 1)
+
+		tree.Set(1, 1)
+		clone = tree.COWClone()
+		clone.Clear()
+		tree.Set(2, 2)
+		t.Assert(tree.Keys(), []interface{}{1, 2})
+		t.Assert(clone.Size(), 0)
+	})
+}
+
+func Test_RedBlackTree_Merge(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		for _, otherSize := range []int{0, 10, 100, 1000} {
+			var (
+				tree  = NewRedBlackTree(gutil.ComparatorInt, true)
+				other = NewRedBlackTree(gutil.ComparatorInt, true)
+				want  = make(map[interface{}]interface{})
+			)
+			for i := 0; i < 200; i++ {
+				tree.Set(i*2, "tree")
+				want[i*2] = "tree"
+			}
+			for i := 0; i < otherSize; i++ {
+				other.Set(i*3, "other")
+				want[i*3] = "other"
+			}
+			tree.Merge(other)
+			t.AssertNil(tree.Check())
+			t.Assert(tree.Map(), want)
+			t.Assert(other.Size(), otherSize)
+		}
+	})
+	gtest.C(t, func(t *gtest.T) {
+		tree := NewRedBlackTreeFrom(gutil.ComparatorInt, map[interface{}]interface{}{1: 1, 2: 2})
+		tree.Merge(nil)
+		tree.Merge(tree)
+		t.Assert(tree.Map(), map[interface{}]interface{}{1: 1, 2: 2})
+
+		// The comparator of the tree is used, in which "1" and 1 are equal.
+		other := NewRedBlackTreeFrom(gutil.ComparatorString, map[interface{}]interface{}{"1": "a", "3": "c"})
+		tree.Merge(other)
+		t.AssertNil(tree.Check())
+		t.Assert(tree.Size(), 3)
+		t.Assert(tree.Get(1), "a")
+		t.Assert(tree.Get(3), "c")
+	})
+}
+
+func Test_RedBlackTree_UpdateFunc(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		tree := NewRedBlackTree(gutil.ComparatorInt, true)
+		for i := 0; i < 100; i++ {
+			tree.Set(i, i)
+		}
+		increase := func(old interface{}, found bool) (interface{}, bool) {
+			if !found {
+				return 1, true
+			}
+			return old.(int) + 1, true
+		}
+		t.Assert(tree.UpdateFunc(10, increase), 11)
+		t.Assert(tree.UpdateFunc(100, increase), 1)
+		t.Assert(tree.Get(10), 11)
+		t.Assert(tree.Get(100), 1)
+		t.Assert(tree.Size(), 101)
+
+		// Removing the key if not kept.
+		remove := func(old interface{}, found bool) (interface{}, bool) {
+			return nil, false
+		}
+		t.Assert(tree.UpdateFunc(20, remove), nil)
+		t.Assert(tree.UpdateFunc(200, remove), nil)
+		t.Assert(tree.Contains(20), false)
+		t.Assert(tree.Size(), 100)
+		t.AssertNil(tree.Check())
+
+		// The value is updated concurrently without losing any increment.
+		wg := sync.WaitGroup{}
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				tree.UpdateFunc(0, increase)
+			}()
+		}
+		wg.Wait()
+		t.Assert(tree.Get(0), 100)
+	})
+	// The shared nodes are copied before updating.
+	gtest.C(t, func(t *gtest.T) {
+		tree := NewRedBlackTree(gutil.ComparatorInt)
+		tree.Set(1, 1)
+		clone := tree.COWClone()
+		clone.UpdateFunc(1, func(old interface{}, found bool) (interface{}, bool) {
+			return old.(int) * 10, true
+		})
+		t.Assert(tree.Get(1), 1)
+		t.Assert(clone.Get(1), 10)
+	})
+}
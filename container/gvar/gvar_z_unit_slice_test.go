@@ -113,3 +113,33 @@ func TestVar_Vars(t *testing.T) {
 		t.Assert(objEmpty.Vars(), nil)
 	})
 }
+
+func TestVar_Slice_Conversion(t *testing.T) {
+	// Already the target type.
+	gtest.C(t, func(t *gtest.T) {
+		t.Assert(gvar.New([]interface{}{1, "a"}).Slice(), []interface{}{1, "a"})
+		t.Assert(gvar.New([]int{1, 2}).Ints(), []int{1, 2})
+		t.Assert(gvar.New([]string{"a", "b"}).Strings(), []string{"a", "b"})
+		t.Assert(gvar.New(map[string]interface{}{"a": 1}).Map(), map[string]interface{}{"a": 1})
+	})
+	// JSON string.
+	gtest.C(t, func(t *gtest.T) {
+		t.Assert(gvar.New(`[1,2,3]`).Ints(), []int{1, 2, 3})
+		t.Assert(gvar.New(`[1,2,3]`).Slice(), []interface{}{1, 2, 3})
+		t.Assert(gvar.New(`{"a":1}`).Map(), map[string]interface{}{"a": 1})
+	})
+	// Comparable slice or map.
+	gtest.C(t, func(t *gtest.T) {
+		t.Assert(gvar.New([]string{"1", "2"}).Ints(), []int{1, 2})
+		t.Assert(gvar.New([]int{1, 2}).Strings(), []string{"1", "2"})
+		t.Assert(gvar.New(map[int]int{1: 2}).Map(), map[string]interface{}{"1": 2})
+	})
+	// Nothing to convert.
+	gtest.C(t, func(t *gtest.T) {
+		t.Assert(len(gvar.New(nil).Slice()), 0)
+		t.Assert(len(gvar.New(nil).Ints()), 0)
+		t.Assert(len(gvar.New(nil).Strings()), 0)
+		t.Assert(len(gvar.New(nil).Map()), 0)
+		t.Assert(len(gvar.New("abc").Map()), 0)
+	})
+}
@@ -75,3 +75,39 @@ func TestVar_Var_Attribute_Struct(t *testing.T) {
 		t.Assert(user.Name, "john")
 	})
 }
+
+func TestVar_Struct_JsonTag(t *testing.T) {
+	type Config struct {
+		Host string `json:"db_host"`
+		Port int    `json:"db_port"`
+	}
+	// Map value.
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			config = new(Config)
+			v      = gvar.New(g.Map{"db_host": "127.0.0.1", "db_port": "3306"})
+		)
+		t.AssertNil(v.Struct(config))
+		t.Assert(config.Host, "127.0.0.1")
+		t.Assert(config.Port, 3306)
+	})
+	// JSON string value.
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			config = new(Config)
+			v      = gvar.New(`{"db_host":"localhost","db_port":3307}`)
+		)
+		t.AssertNil(v.Struct(config))
+		t.Assert(config.Host, "localhost")
+		t.Assert(config.Port, 3307)
+	})
+	// Mismatch.
+	gtest.C(t, func(t *gtest.T) {
+		var (
+			config = new(Config)
+			v      = gvar.New(`{"db_host":"localhost"}`)
+		)
+		t.AssertNE(v.Struct(*config), nil)
+		t.AssertNE(gvar.New("not a map").Struct(config), nil)
+	})
+}
@@ -20,6 +20,10 @@ import (
 // be used to delay JSON decoding or precompute a JSON encoding.
 type RawMessage = json.RawMessage
 
+// Delim is a JSON array or object delimiter, one of [ ] { or },
+// which is returned by the Token method of Decoder.
+type Delim = json.Delim
+
 // Marshal adapts to json/encoding Marshal API.
 //
 // Marshal returns the JSON encoding of v, adapts to json/encoding Marshal API
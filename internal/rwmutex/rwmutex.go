@@ -75,3 +75,21 @@ func (mu *RWMutex) RUnlock() {
 		mu.mutex.RUnlock()
 	}
 }
+
+// TryLock tries to lock mutex for writing and reports whether it succeeds.
+// It always returns true if it is not in concurrent-safe usage.
+func (mu *RWMutex) TryLock() bool {
+	if mu.mutex != nil {
+		return mu.mutex.TryLock()
+	}
+	return true
+}
+
+// TryRLock tries to lock mutex for reading and reports whether it succeeds.
+// It always returns true if it is not in concurrent-safe usage.
+func (mu *RWMutex) TryRLock() bool {
+	if mu.mutex != nil {
+		return mu.mutex.TryRLock()
+	}
+	return true
+}
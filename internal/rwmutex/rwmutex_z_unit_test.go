@@ -141,3 +141,27 @@ func TestUnsafeRWMutex(t *testing.T) {
 		t.Assert(array.Len(), 4)
 	})
 }
+
+func TestRWMutexTryLock(t *testing.T) {
+	gtest.C(t, func(t *gtest.T) {
+		lock := rwmutex.New()
+		t.Assert(lock.TryLock(), true)
+		t.Assert(lock.TryLock(), true)
+		t.Assert(lock.TryRLock(), true)
+	})
+	gtest.C(t, func(t *gtest.T) {
+		lock := rwmutex.New(true)
+		t.Assert(lock.TryLock(), true)
+		t.Assert(lock.TryLock(), false)
+		t.Assert(lock.TryRLock(), false)
+		lock.Unlock()
+
+		t.Assert(lock.TryRLock(), true)
+		t.Assert(lock.TryRLock(), true)
+		t.Assert(lock.TryLock(), false)
+		lock.RUnlock()
+		lock.RUnlock()
+		t.Assert(lock.TryLock(), true)
+		lock.Unlock()
+	})
+}